@@ -0,0 +1,111 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ripemd160Size is the size of the hash160 payload of pay-to-pubkey-hash and
+// pay-to-script-hash addresses.
+const ripemd160Size = 20
+
+var (
+	// ErrInvalidAddressLength describes an error where a base58check
+	// decoded address does not hold a single version byte followed by a
+	// 20 byte hash.
+	ErrInvalidAddressLength = errors.New("invalid address payload length")
+
+	// ErrUnknownAddressID describes an error where the version byte of an
+	// address is not used as a pay-to-pubkey-hash or pay-to-script-hash
+	// address magic by any default or registered network.
+	ErrUnknownAddressID = errors.New("unknown address version byte")
+
+	// ErrAmbiguousAddressID describes an error where the version byte of
+	// an address is used as a pay-to-pubkey-hash address magic by one
+	// network and as a pay-to-script-hash address magic by another, so
+	// the kind of address can not be determined.
+	ErrAmbiguousAddressID = errors.New("ambiguous address version byte")
+)
+
+// AddressKind identifies the kind of payment an encoded address pays to.
+type AddressKind int
+
+// These constants define the kinds of base58check encoded addresses.
+const (
+	// PubKeyHashAddress is a pay-to-pubkey-hash address.
+	PubKeyHashAddress AddressKind = iota
+
+	// ScriptHashAddress is a pay-to-script-hash address.
+	ScriptHashAddress
+)
+
+// addressKindStrings is a map of address kinds back to their constant names
+// for pretty printing.
+var addressKindStrings = map[AddressKind]string{
+	PubKeyHashAddress: "PubKeyHashAddress",
+	ScriptHashAddress: "ScriptHashAddress",
+}
+
+// String returns the AddressKind in human-readable form.
+func (k AddressKind) String() string {
+	if s, ok := addressKindStrings[k]; ok {
+		return s
+	}
+	return fmt.Sprintf("Unknown AddressKind (%d)", int(k))
+}
+
+// decodeAddress decodes a base58check encoded pay-to-pubkey-hash or
+// pay-to-script-hash address into its version byte and 20 byte hash.
+func decodeAddress(addr string) (byte, []byte, error) {
+	decoded, err := checkDecode(addr)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(decoded) != 1+ripemd160Size {
+		return 0, nil, ErrInvalidAddressLength
+	}
+	return decoded[0], decoded[1:], nil
+}
+
+// DetectAddressNetwork decodes the passed base58check encoded address and
+// returns whether it is a pay-to-pubkey-hash or pay-to-script-hash address
+// along with every default or registered network which uses its version
+// byte for that kind of address.  More than one network is returned when
+// networks share address magics, as is the case with regtest and testnet3.
+// Networks are returned in the order they were registered.
+//
+// ErrInvalidBase58, ErrInvalidFormat, ErrChecksumMismatch, or
+// ErrInvalidAddressLength is returned when the string is not a well formed
+// address.  ErrUnknownAddressID is returned when no network uses the version
+// byte and ErrAmbiguousAddressID when the kind of address can not be
+// determined.
+func DetectAddressNetwork(addr string) (AddressKind, []*Params, error) {
+	id, _, err := decodeAddress(addr)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var p2pkh, p2sh []*Params
+	for _, params := range registeredParams {
+		if params.PubKeyHashAddrID == id {
+			p2pkh = append(p2pkh, params)
+		}
+		if params.ScriptHashAddrID == id {
+			p2sh = append(p2sh, params)
+		}
+	}
+
+	switch {
+	case len(p2pkh) != 0 && len(p2sh) != 0:
+		return 0, nil, ErrAmbiguousAddressID
+	case len(p2pkh) != 0:
+		return PubKeyHashAddress, p2pkh, nil
+	case len(p2sh) != 0:
+		return ScriptHashAddress, p2sh, nil
+	}
+	return 0, nil, ErrUnknownAddressID
+}
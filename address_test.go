@@ -0,0 +1,159 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/reddcoin-project/rddnet"
+)
+
+// TestDetectAddressNetwork ensures encoded addresses are matched with the
+// networks which use their version bytes and that malformed addresses are
+// rejected with the expected errors.
+func TestDetectAddressNetwork(t *testing.T) {
+	// Register two networks where the script hash magic of the first is
+	// the pubkey hash magic of the second.
+	ambiguousNets := []*rddnet.Params{
+		{
+			Name:             "ambiguousnet1",
+			Net:              0xffffffe0,
+			PubKeyHashAddrID: 0xa0,
+			ScriptHashAddrID: 0xa1,
+			HDPrivateKeyID:   [4]byte{0x20, 0x21, 0x22, 0x23},
+			HDPublicKeyID:    [4]byte{0x24, 0x25, 0x26, 0x27},
+		},
+		{
+			Name:             "ambiguousnet2",
+			Net:              0xffffffe1,
+			PubKeyHashAddrID: 0xa1,
+			ScriptHashAddrID: 0xa2,
+			HDPrivateKeyID:   [4]byte{0x28, 0x29, 0x2a, 0x2b},
+			HDPublicKeyID:    [4]byte{0x2c, 0x2d, 0x2e, 0x2f},
+		},
+	}
+	for _, params := range ambiguousNets {
+		if err := rddnet.TstRegister(t, params); err != nil {
+			t.Fatalf("Register %s: %v", params.Name, err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		addr string
+		kind rddnet.AddressKind
+		nets []*rddnet.Params
+		err  error
+	}{
+		{
+			name: "mainnet p2pkh",
+			addr: "RuLeTmnXHQsiNjuDp512QYgoJ5jmPTs5UX",
+			kind: rddnet.PubKeyHashAddress,
+			nets: []*rddnet.Params{&rddnet.MainNetParams},
+		},
+		{
+			name: "mainnet p2sh",
+			addr: "3NQsKh6PXJveaU6NSaMBFXSk3qJwZDt1xm",
+			kind: rddnet.ScriptHashAddress,
+			nets: []*rddnet.Params{&rddnet.MainNetParams},
+		},
+		{
+			name: "testnet3 and regtest p2pkh",
+			addr: "n2EohCgvnS3XGQsZ33exepJ8mJcvujsjzm",
+			kind: rddnet.PubKeyHashAddress,
+			nets: []*rddnet.Params{
+				&rddnet.TestNet3Params,
+				&rddnet.RegressionNetParams,
+			},
+		},
+		{
+			name: "testnet3 and regtest p2sh",
+			addr: "2NDy5PS2R8mRznFiv7hy3sUS1GBX7KsHDpu",
+			kind: rddnet.ScriptHashAddress,
+			nets: []*rddnet.Params{
+				&rddnet.TestNet3Params,
+				&rddnet.RegressionNetParams,
+			},
+		},
+		{
+			name: "simnet p2pkh",
+			addr: "Si1rRzP6hmoU1cBPruffNoENZ6Fem6DgGd",
+			kind: rddnet.PubKeyHashAddress,
+			nets: []*rddnet.Params{&rddnet.SimNetParams},
+		},
+		{
+			name: "simnet p2sh",
+			addr: "rrJ3WWGPJbc35cYbL5enUKZaKMiFV8xXpr",
+			kind: rddnet.ScriptHashAddress,
+			nets: []*rddnet.Params{&rddnet.SimNetParams},
+		},
+		{
+			name: "bitcoin mainnet p2pkh",
+			addr: "1MirQ9bwyQcGVJPwKUgapu5ouK2E2Ey4gX",
+			err:  rddnet.ErrUnknownAddressID,
+		},
+		{
+			name: "ambiguous version byte",
+			addr: "2898xvdbLHTDLA5qtG2Jtu5uUEXW6WvFvx6",
+			err:  rddnet.ErrAmbiguousAddressID,
+		},
+		{
+			name: "bad checksum",
+			addr: "RuLeTmnXHQsiNjuDp512QYgoJ5jmLNzxF1",
+			err:  rddnet.ErrChecksumMismatch,
+		},
+		{
+			name: "short hash",
+			addr: "6eEWnVGkZifDzwaaiUeAP6pXsziMhW4R7",
+			err:  rddnet.ErrInvalidAddressLength,
+		},
+		{
+			name: "invalid character",
+			addr: "RuLeTmnXHQsiNjuDp512QYgoJ5jmPTs5U0",
+			err:  rddnet.ErrInvalidBase58,
+		},
+		{
+			name: "too short",
+			addr: "1111",
+			err:  rddnet.ErrInvalidFormat,
+		},
+		{
+			name: "empty",
+			addr: "",
+			err:  rddnet.ErrInvalidFormat,
+		},
+	}
+
+	for _, test := range tests {
+		kind, nets, err := rddnet.DetectAddressNetwork(test.addr)
+		if err != test.err {
+			t.Errorf("%s: unexpected error: got %v, want %v", test.name,
+				err, test.err)
+			continue
+		}
+		if test.err != nil {
+			continue
+		}
+		if kind != test.kind {
+			t.Errorf("%s: mismatched kind: got %v, want %v", test.name,
+				kind, test.kind)
+		}
+		if !reflect.DeepEqual(nets, test.nets) {
+			t.Errorf("%s: mismatched networks: got %v, want %v",
+				test.name, paramsNames(nets), paramsNames(test.nets))
+		}
+	}
+}
+
+// paramsNames returns the names of the passed networks for use in test error
+// messages.
+func paramsNames(nets []*rddnet.Params) []string {
+	names := make([]string, 0, len(nets))
+	for _, params := range nets {
+		names = append(names, params.Name)
+	}
+	return names
+}
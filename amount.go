@@ -0,0 +1,40 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet
+
+// These constants define the units of Reddcoin amounts.  Amounts are always
+// expressed in satoshi, the smallest unit, on the wire and in Params.
+const (
+	// SatoshiPerReddcent is the number of satoshi in one reddcoin cent.
+	SatoshiPerReddcent int64 = 1e6
+
+	// SatoshiPerReddcoin is the number of satoshi in one reddcoin
+	// (1 RDD).
+	SatoshiPerReddcoin int64 = 1e8
+
+	// MaxSatoshi is the maximum transaction amount allowed in satoshi on
+	// the default networks.  Unlike Bitcoin, Reddcoin limits amounts to
+	// the largest whole number of reddcoins which fits in an int64.
+	MaxSatoshi int64 = 92233720368 * SatoshiPerReddcoin
+)
+
+// IsValidAmount returns whether the passed amount in satoshi is within the
+// range allowed for a transaction output, or the sum of outputs, on the
+// network.  Negative amounts and amounts greater than MaxMoney are invalid.
+func (p *Params) IsValidAmount(amount int64) bool {
+	return amount >= 0 && amount <= p.MaxMoney
+}
+
+// IsMature returns whether the outputs of a coinbase or coinstake transaction
+// included in the block at txHeight may be spent by a transaction in the block
+// after the block at tipHeight, such as a transaction entering the mempool.
+// When validating a block, tipHeight is the height of its parent.
+func (p *Params) IsMature(txHeight, tipHeight int64, isCoinstake bool) bool {
+	maturity := int64(p.CoinbaseMaturity)
+	if isCoinstake {
+		maturity = int64(p.CoinstakeMaturity)
+	}
+	return tipHeight+1-txHeight >= maturity
+}
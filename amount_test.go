@@ -0,0 +1,104 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/reddcoin-project/rddnet"
+)
+
+// defaultNets are the parameters of the default networks.
+var defaultNets = []*rddnet.Params{
+	&rddnet.MainNetParams,
+	&rddnet.RegressionNetParams,
+	&rddnet.TestNet3Params,
+	&rddnet.SimNetParams,
+}
+
+// TestIsValidAmount ensures amounts are only valid between zero and the
+// maximum amount of each default network, inclusive.
+func TestIsValidAmount(t *testing.T) {
+	if rddnet.MaxSatoshi <= math.MaxInt64-rddnet.SatoshiPerReddcoin {
+		t.Errorf("MaxSatoshi %d is not the largest whole number of "+
+			"reddcoins", rddnet.MaxSatoshi)
+	}
+
+	for _, params := range defaultNets {
+		if params.MaxMoney != rddnet.MaxSatoshi {
+			t.Errorf("%s: MaxMoney %d, want %d", params.Name,
+				params.MaxMoney, rddnet.MaxSatoshi)
+		}
+
+		tests := []struct {
+			amount int64
+			want   bool
+		}{
+			{math.MinInt64, false},
+			{-1, false},
+			{0, true},
+			{1, true},
+			{rddnet.SatoshiPerReddcoin, true},
+			{params.MaxMoney - 1, true},
+			{params.MaxMoney, true},
+			{params.MaxMoney + 1, false},
+			{math.MaxInt64, false},
+		}
+		for _, test := range tests {
+			got := params.IsValidAmount(test.amount)
+			if got != test.want {
+				t.Errorf("%s: IsValidAmount(%d): got %v want %v",
+					params.Name, test.amount, got, test.want)
+			}
+		}
+	}
+}
+
+// TestIsMature ensures coinbase and coinstake outputs become spendable in
+// exactly the block their maturity allows on each default network.
+func TestIsMature(t *testing.T) {
+	const txHeight = 1000
+	for _, params := range defaultNets {
+		for _, isCoinstake := range []bool{false, true} {
+			maturity := int64(params.CoinbaseMaturity)
+			if isCoinstake {
+				maturity = int64(params.CoinstakeMaturity)
+			}
+
+			// The spending block is the one after the tip, so the
+			// outputs mature once the tip is maturity-1 blocks
+			// past the block including the transaction.
+			tests := []struct {
+				tipHeight int64
+				want      bool
+			}{
+				{txHeight, maturity <= 1},
+				{txHeight + maturity - 2, false},
+				{txHeight + maturity - 1, true},
+				{txHeight + maturity, true},
+			}
+			for _, test := range tests {
+				got := params.IsMature(txHeight, test.tipHeight,
+					isCoinstake)
+				if got != test.want {
+					t.Errorf("%s: IsMature(%d, %d, %v): got %v "+
+						"want %v", params.Name, txHeight,
+						test.tipHeight, isCoinstake, got,
+						test.want)
+				}
+			}
+		}
+	}
+
+	// Reddcoin outputs mature sooner than Bitcoin's 100 blocks.
+	if rddnet.MainNetParams.CoinbaseMaturity != 50 ||
+		rddnet.MainNetParams.CoinstakeMaturity != 50 {
+
+		t.Errorf("mainnet: unexpected maturities %d and %d",
+			rddnet.MainNetParams.CoinbaseMaturity,
+			rddnet.MainNetParams.CoinstakeMaturity)
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2013-2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+
+	"github.com/reddcoin-project/rddwire"
+)
+
+// alphabet is the modified base58 alphabet used by Reddcoin.
+const alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var (
+	// ErrInvalidBase58 describes an error where a string could not be
+	// decoded due to containing characters outside of the base58 alphabet.
+	ErrInvalidBase58 = errors.New("invalid base58 string")
+
+	// ErrChecksumMismatch describes an error where decoding failed due to
+	// a bad checksum.
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+
+	// ErrInvalidFormat describes an error where decoding failed due to
+	// the decoded data being too short to hold a version and checksum.
+	ErrInvalidFormat = errors.New("invalid format: version and/or " +
+		"checksum bytes missing")
+)
+
+// bigRadix is 58 represented as a big.Int.  It is defined here to avoid the
+// overhead of creating it multiple times.
+var bigRadix = big.NewInt(58)
+
+// base58Decode decodes a modified base58 string to a byte slice.
+func base58Decode(b string) ([]byte, error) {
+	answer := new(big.Int)
+	scratch := new(big.Int)
+	for i := 0; i < len(b); i++ {
+		tmp := bytes.IndexByte([]byte(alphabet), b[i])
+		if tmp == -1 {
+			return nil, ErrInvalidBase58
+		}
+		scratch.SetInt64(int64(tmp))
+		answer.Mul(answer, bigRadix)
+		answer.Add(answer, scratch)
+	}
+
+	tmpval := answer.Bytes()
+
+	var numZeros int
+	for numZeros = 0; numZeros < len(b); numZeros++ {
+		if b[numZeros] != alphabet[0] {
+			break
+		}
+	}
+	flen := numZeros + len(tmpval)
+	val := make([]byte, flen)
+	copy(val[numZeros:], tmpval)
+
+	return val, nil
+}
+
+// base58Encode encodes a byte slice to a modified base58 string.
+func base58Encode(b []byte) string {
+	x := new(big.Int).SetBytes(b)
+
+	answer := make([]byte, 0, len(b)*136/100)
+	mod := new(big.Int)
+	for x.Sign() > 0 {
+		x.DivMod(x, bigRadix, mod)
+		answer = append(answer, alphabet[mod.Int64()])
+	}
+
+	// Leading zero bytes are encoded as leading ones.
+	for _, i := range b {
+		if i != 0 {
+			break
+		}
+		answer = append(answer, alphabet[0])
+	}
+
+	// Reverse.
+	alen := len(answer)
+	for i := 0; i < alen/2; i++ {
+		answer[i], answer[alen-1-i] = answer[alen-1-i], answer[i]
+	}
+
+	return string(answer)
+}
+
+// checksum returns the first four bytes of the double SHA256 of the input.
+func checksum(input []byte) (cksum [4]byte) {
+	copy(cksum[:], rddwire.DoubleSha256(input)[:4])
+	return
+}
+
+// checkEncode prepends the version bytes and appends a four byte checksum to
+// the payload before base58 encoding it.
+func checkEncode(input []byte, version []byte) string {
+	b := make([]byte, 0, len(version)+len(input)+4)
+	b = append(b, version...)
+	b = append(b, input...)
+	cksum := checksum(b)
+	b = append(b, cksum[:]...)
+	return base58Encode(b)
+}
+
+// checkDecode decodes a base58check string and verifies its checksum.  It
+// returns the decoded data, including the version bytes, with the checksum
+// removed.
+func checkDecode(input string) ([]byte, error) {
+	decoded, err := base58Decode(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(decoded) < 5 {
+		return nil, ErrInvalidFormat
+	}
+	var cksum [4]byte
+	copy(cksum[:], decoded[len(decoded)-4:])
+	if checksum(decoded[:len(decoded)-4]) != cksum {
+		return nil, ErrChecksumMismatch
+	}
+	return decoded[:len(decoded)-4], nil
+}
@@ -0,0 +1,389 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet
+
+import (
+	"math/big"
+
+	"github.com/reddcoin-project/rddwire"
+)
+
+// copyShaHash returns a copy of the passed hash or nil when it is nil.
+func copyShaHash(hash *rddwire.ShaHash) *rddwire.ShaHash {
+	if hash == nil {
+		return nil
+	}
+	newHash := *hash
+	return &newHash
+}
+
+// copyBigInt returns a copy of the passed big integer or nil when it is nil.
+func copyBigInt(n *big.Int) *big.Int {
+	if n == nil {
+		return nil
+	}
+	return new(big.Int).Set(n)
+}
+
+// copyBytes returns a copy of the passed byte slice.  A nil slice is returned
+// as nil.
+func copyBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append([]byte{}, b...)
+}
+
+// copyMsgTx returns a deep copy of the passed transaction.
+func copyMsgTx(tx *rddwire.MsgTx) *rddwire.MsgTx {
+	if tx == nil {
+		return nil
+	}
+	newTx := *tx
+	if tx.TxIn != nil {
+		newTx.TxIn = make([]*rddwire.TxIn, len(tx.TxIn))
+		for i, txIn := range tx.TxIn {
+			if txIn == nil {
+				continue
+			}
+			newTxIn := *txIn
+			newTxIn.SignatureScript = copyBytes(txIn.SignatureScript)
+			newTx.TxIn[i] = &newTxIn
+		}
+	}
+	if tx.TxOut != nil {
+		newTx.TxOut = make([]*rddwire.TxOut, len(tx.TxOut))
+		for i, txOut := range tx.TxOut {
+			if txOut == nil {
+				continue
+			}
+			newTxOut := *txOut
+			newTxOut.PkScript = copyBytes(txOut.PkScript)
+			newTx.TxOut[i] = &newTxOut
+		}
+	}
+	return &newTx
+}
+
+// copyMsgBlock returns a deep copy of the passed block including all of its
+// transactions.
+func copyMsgBlock(block *rddwire.MsgBlock) *rddwire.MsgBlock {
+	if block == nil {
+		return nil
+	}
+	newBlock := *block
+	if block.Transactions != nil {
+		newBlock.Transactions = make([]*rddwire.MsgTx, len(block.Transactions))
+		for i, tx := range block.Transactions {
+			newBlock.Transactions[i] = copyMsgTx(tx)
+		}
+	}
+	return &newBlock
+}
+
+// copyCheckpoints returns a deep copy of the passed checkpoints.
+func copyCheckpoints(checkpoints []Checkpoint) []Checkpoint {
+	if checkpoints == nil {
+		return nil
+	}
+	newCheckpoints := make([]Checkpoint, len(checkpoints))
+	for i, checkpoint := range checkpoints {
+		newCheckpoints[i] = Checkpoint{
+			Height: checkpoint.Height,
+			Hash:   copyShaHash(checkpoint.Hash),
+		}
+	}
+	return newCheckpoints
+}
+
+// copyFeatureVersions returns a copy of the passed feature gate table.  A nil
+// table is returned as nil.
+func copyFeatureVersions(table map[ProtocolFeature]uint32) map[ProtocolFeature]uint32 {
+	if table == nil {
+		return nil
+	}
+	newTable := make(map[ProtocolFeature]uint32, len(table))
+	for feature, version := range table {
+		newTable[feature] = version
+	}
+	return newTable
+}
+
+// copyHeaderSnapshots returns a deep copy of the passed header snapshots.
+func copyHeaderSnapshots(snapshots []HeaderSnapshot) []HeaderSnapshot {
+	if snapshots == nil {
+		return nil
+	}
+	newSnapshots := make([]HeaderSnapshot, len(snapshots))
+	for i := range snapshots {
+		newSnapshots[i] = *copyHeaderSnapshot(&snapshots[i])
+	}
+	return newSnapshots
+}
+
+// copyPubKeys returns a deep copy of the passed serialized public keys.  A nil
+// slice is returned as nil.
+func copyPubKeys(keys [][]byte) [][]byte {
+	if keys == nil {
+		return nil
+	}
+	newKeys := make([][]byte, len(keys))
+	for i, key := range keys {
+		newKeys[i] = append([]byte(nil), key...)
+	}
+	return newKeys
+}
+
+// Clone returns a deep copy of the network parameters.  No memory is shared
+// between the parameters and the returned copy, including the genesis block
+// and its transactions, so either may be modified without affecting the
+// other.  The returned copy is not registered.
+func (p *Params) Clone() *Params {
+	clone := *p
+	if p.Aliases != nil {
+		clone.Aliases = append([]string{}, p.Aliases...)
+	}
+	clone.GenesisBlock = copyMsgBlock(p.GenesisBlock)
+	clone.GenesisHash = copyShaHash(p.GenesisHash)
+	clone.PowLimit = copyBigInt(p.PowLimit)
+	if p.BlockLimits != nil {
+		clone.BlockLimits = append([]BlockLimits{}, p.BlockLimits...)
+	}
+	clone.Checkpoints = copyCheckpoints(p.Checkpoints)
+	clone.HeaderSnapshots = copyHeaderSnapshots(p.HeaderSnapshots)
+	clone.CheckpointPubKeys = copyPubKeys(p.CheckpointPubKeys)
+	clone.SignetChallenge = copyBytes(p.SignetChallenge)
+	clone.FeatureVersions = copyFeatureVersions(p.FeatureVersions)
+	return &clone
+}
+
+// FrozenParams is a read-only view of network parameters.  Every field of
+// Params other than the deprecated RelayNonStdTxs has an accessor of the same
+// name.  The parameters are copied when frozen and every accessor returns a
+// defensive copy, so neither later modifications of the original parameters nor
+// modifications of values returned by the accessors are visible through it.
+// This makes it safe to hand out to code which must not be able to change the
+// parameters used by the rest of the process.
+//
+// The zero value is not valid.  Use Freeze to create a FrozenParams.
+type FrozenParams struct {
+	params *Params
+}
+
+// Freeze returns a read-only view of a deep copy of the network parameters.
+func (p *Params) Freeze() FrozenParams {
+	return FrozenParams{params: p.Clone()}
+}
+
+// Params returns a deep copy of the frozen network parameters which may be
+// freely modified.
+func (f FrozenParams) Params() *Params {
+	return f.params.Clone()
+}
+
+// Name returns the name of the network.
+func (f FrozenParams) Name() string {
+	return f.params.Name
+}
+
+// Net returns the magic identifying the network on the wire.
+func (f FrozenParams) Net() rddwire.ReddcoinNet {
+	return f.params.Net
+}
+
+// DefaultPort returns the default peer-to-peer port of the network.
+func (f FrozenParams) DefaultPort() string {
+	return f.params.DefaultPort
+}
+
+// P2PPort returns the default peer-to-peer port of the network as a number.
+func (f FrozenParams) P2PPort() uint16 {
+	return f.params.P2PPort
+}
+
+// RPCPort returns the default RPC server port of the network.
+func (f FrozenParams) RPCPort() uint16 {
+	return f.params.RPCPort
+}
+
+// WSPort returns the default websocket notification server port of the
+// network.
+func (f FrozenParams) WSPort() uint16 {
+	return f.params.WSPort
+}
+
+// Aliases returns a copy of the other names the network is known by.
+func (f FrozenParams) Aliases() []string {
+	if f.params.Aliases == nil {
+		return nil
+	}
+	return append([]string{}, f.params.Aliases...)
+}
+
+// GenesisBlock returns a deep copy of the genesis block of the network.
+func (f FrozenParams) GenesisBlock() *rddwire.MsgBlock {
+	return copyMsgBlock(f.params.GenesisBlock)
+}
+
+// GenesisHash returns a copy of the hash of the genesis block of the network.
+func (f FrozenParams) GenesisHash() *rddwire.ShaHash {
+	return copyShaHash(f.params.GenesisHash)
+}
+
+// PowLimit returns a copy of the highest proof of work value a block can have
+// on the network.
+func (f FrozenParams) PowLimit() *big.Int {
+	return copyBigInt(f.params.PowLimit)
+}
+
+// PowLimitBits returns the proof of work limit of the network in the compact
+// form used by block headers.
+func (f FrozenParams) PowLimitBits() uint32 {
+	return f.params.PowLimitBits
+}
+
+// SubsidyHalvingInterval returns the number of blocks between reductions of
+// the block subsidy.
+func (f FrozenParams) SubsidyHalvingInterval() int32 {
+	return f.params.SubsidyHalvingInterval
+}
+
+// ResetMinDifficulty returns whether the network allows blocks with the
+// minimum difficulty after a long time without a block.
+func (f FrozenParams) ResetMinDifficulty() bool {
+	return f.params.ResetMinDifficulty
+}
+
+// PoSVTxVersion returns the lowest version of transactions which use the PoSV
+// format.
+func (f FrozenParams) PoSVTxVersion() int32 {
+	return f.params.PoSVTxVersion
+}
+
+// CoinbaseMaturity returns the number of blocks which must follow a coinbase
+// before its outputs may be spent.
+func (f FrozenParams) CoinbaseMaturity() uint16 {
+	return f.params.CoinbaseMaturity
+}
+
+// CoinstakeMaturity returns the number of blocks which must follow a coinstake
+// before its outputs may be spent.
+func (f FrozenParams) CoinstakeMaturity() uint16 {
+	return f.params.CoinstakeMaturity
+}
+
+// MaxMoney returns the maximum amount in satoshi of a transaction output.
+func (f FrozenParams) MaxMoney() int64 {
+	return f.params.MaxMoney
+}
+
+// BlockLimits returns a copy of the block limits of the network ordered from
+// oldest to newest.
+func (f FrozenParams) BlockLimits() []BlockLimits {
+	if f.params.BlockLimits == nil {
+		return nil
+	}
+	return append([]BlockLimits{}, f.params.BlockLimits...)
+}
+
+// Checkpoints returns a deep copy of the checkpoints of the network ordered
+// from oldest to newest.
+func (f FrozenParams) Checkpoints() []Checkpoint {
+	return copyCheckpoints(f.params.Checkpoints)
+}
+
+// HeaderSnapshots returns a deep copy of the header snapshots of the network
+// ordered from oldest to newest.
+func (f FrozenParams) HeaderSnapshots() []HeaderSnapshot {
+	return copyHeaderSnapshots(f.params.HeaderSnapshots)
+}
+
+// CheckpointPubKeys returns a deep copy of the serialized public keys of the
+// checkpoint masters of the network.
+func (f FrozenParams) CheckpointPubKeys() [][]byte {
+	return copyPubKeys(f.params.CheckpointPubKeys)
+}
+
+// SignetChallenge returns a copy of the signet challenge script of the
+// network.
+func (f FrozenParams) SignetChallenge() []byte {
+	return copyBytes(f.params.SignetChallenge)
+}
+
+// BlockV1RejectNumRequired returns the number of recent blocks which must be
+// version 2 or newer before version 1 blocks are rejected.
+func (f FrozenParams) BlockV1RejectNumRequired() uint64 {
+	return f.params.BlockV1RejectNumRequired
+}
+
+// BlockV1RejectNumToCheck returns the number of recent blocks checked when
+// deciding whether version 1 blocks are rejected.
+func (f FrozenParams) BlockV1RejectNumToCheck() uint64 {
+	return f.params.BlockV1RejectNumToCheck
+}
+
+// CoinbaseBlockHeightNumRequired returns the number of recent blocks which must
+// be version 2 or newer before coinbases must start with the block height.
+func (f FrozenParams) CoinbaseBlockHeightNumRequired() uint64 {
+	return f.params.CoinbaseBlockHeightNumRequired
+}
+
+// CoinbaseBlockHeightNumToCheck returns the number of recent blocks checked
+// when deciding whether coinbases must start with the block height.
+func (f FrozenParams) CoinbaseBlockHeightNumToCheck() uint64 {
+	return f.params.CoinbaseBlockHeightNumToCheck
+}
+
+// MinProtocolVersion returns the lowest protocol version peers of the network
+// may negotiate.
+func (f FrozenParams) MinProtocolVersion() uint32 {
+	return f.params.MinProtocolVersion
+}
+
+// FeatureVersions returns a copy of the protocol versions which enable each
+// feature on the network.
+func (f FrozenParams) FeatureVersions() map[ProtocolFeature]uint32 {
+	return copyFeatureVersions(f.params.FeatureVersions)
+}
+
+// Policy returns the default relay and mining policy of the network.
+func (f FrozenParams) Policy() Policy {
+	return f.params.Policy
+}
+
+// PubKeyHashAddrID returns the first byte of pay-to-pubkey-hash addresses.
+func (f FrozenParams) PubKeyHashAddrID() byte {
+	return f.params.PubKeyHashAddrID
+}
+
+// ScriptHashAddrID returns the first byte of pay-to-script-hash addresses.
+func (f FrozenParams) ScriptHashAddrID() byte {
+	return f.params.ScriptHashAddrID
+}
+
+// PrivateKeyID returns the first byte of WIF private keys.
+func (f FrozenParams) PrivateKeyID() byte {
+	return f.params.PrivateKeyID
+}
+
+// Bech32HRPSegwit returns the human-readable part of Bech32 segwit addresses.
+func (f FrozenParams) Bech32HRPSegwit() string {
+	return f.params.Bech32HRPSegwit
+}
+
+// HDPrivateKeyID returns the magic of extended private keys.
+func (f FrozenParams) HDPrivateKeyID() [4]byte {
+	return f.params.HDPrivateKeyID
+}
+
+// HDPublicKeyID returns the magic of extended public keys.
+func (f FrozenParams) HDPublicKeyID() [4]byte {
+	return f.params.HDPublicKeyID
+}
+
+// HDCoinType returns the BIP0044 coin type of the network.
+func (f FrozenParams) HDCoinType() uint32 {
+	return f.params.HDCoinType
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet_test
+
+import (
+	"bytes"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/reddcoin-project/rddnet"
+	"github.com/reddcoin-project/rddwire"
+)
+
+// serializedGenesis returns the serialized genesis block of the passed network.
+func serializedGenesis(t *testing.T, params *rddnet.Params) []byte {
+	var buf bytes.Buffer
+	if err := params.GenesisBlock.Serialize(&buf); err != nil {
+		t.Fatalf("%s: Serialize: %v", params.Name, err)
+	}
+	return buf.Bytes()
+}
+
+// mutateParams modifies every reference value held by the passed parameters in
+// place.
+func mutateParams(params *rddnet.Params) {
+	params.PowLimit.SetInt64(1)
+	params.GenesisHash[0] ^= 0xff
+	params.GenesisBlock.Header.Nonce++
+	params.GenesisBlock.Header.MerkleRoot[0] ^= 0xff
+	tx := params.GenesisBlock.Transactions[0]
+	tx.TxIn[0].SignatureScript[0] ^= 0xff
+	tx.TxIn[0].PreviousOutPoint.Index = 0
+	tx.TxOut[0].PkScript[0] ^= 0xff
+	tx.TxOut[0].Value = 0
+	tx.TxOut = append(tx.TxOut, &rddwire.TxOut{})
+	params.GenesisBlock.Transactions = append(
+		params.GenesisBlock.Transactions, rddwire.NewMsgTx())
+	if len(params.Checkpoints) > 0 {
+		params.Checkpoints[0].Height = -1
+		params.Checkpoints[0].Hash[0] ^= 0xff
+	}
+	params.Checkpoints = append(params.Checkpoints, rddnet.Checkpoint{})
+}
+
+// TestClone ensures clones of the default networks are equal to the originals
+// and that modifying a clone does not modify the original.
+func TestClone(t *testing.T) {
+	nets := []*rddnet.Params{
+		&rddnet.MainNetParams,
+		&rddnet.RegressionNetParams,
+		&rddnet.TestNet3Params,
+		&rddnet.SimNetParams,
+	}
+	for _, params := range nets {
+		genesis := serializedGenesis(t, params)
+		genesisHash := *params.GenesisHash
+		powLimit := new(big.Int).Set(params.PowLimit)
+		numCheckpoints := len(params.Checkpoints)
+		var checkpoint rddnet.Checkpoint
+		if numCheckpoints > 0 {
+			checkpoint = params.Checkpoints[0]
+			hash := *params.Checkpoints[0].Hash
+			checkpoint.Hash = &hash
+		}
+
+		clone := params.Clone()
+		if !reflect.DeepEqual(clone, params) {
+			t.Errorf("%s: clone is not equal to the original",
+				params.Name)
+			continue
+		}
+
+		mutateParams(clone)
+		if !bytes.Equal(serializedGenesis(t, params), genesis) {
+			t.Errorf("%s: genesis block modified through clone",
+				params.Name)
+		}
+		if !params.GenesisHash.IsEqual(&genesisHash) {
+			t.Errorf("%s: genesis hash modified through clone",
+				params.Name)
+		}
+		if params.PowLimit.Cmp(powLimit) != 0 {
+			t.Errorf("%s: pow limit modified through clone",
+				params.Name)
+		}
+		if len(params.Checkpoints) != numCheckpoints {
+			t.Errorf("%s: checkpoints modified through clone",
+				params.Name)
+		}
+		if numCheckpoints > 0 &&
+			!reflect.DeepEqual(params.Checkpoints[0], checkpoint) {
+			t.Errorf("%s: checkpoint modified through clone",
+				params.Name)
+		}
+	}
+
+	// Parameters without a genesis block or checkpoints can be cloned.
+	empty := &rddnet.Params{Name: "empty"}
+	if clone := empty.Clone(); !reflect.DeepEqual(clone, empty) {
+		t.Errorf("empty: clone is not equal to the original")
+	}
+}
+
+// TestFreeze ensures frozen parameters are not affected by modifications of
+// the original parameters or of the values returned by their accessors.
+func TestFreeze(t *testing.T) {
+	params := rddnet.MainNetParams.Clone()
+	genesis := serializedGenesis(t, params)
+	frozen := params.Freeze()
+
+	// Modify the original after freezing.
+	mutateParams(params)
+	params.Name = "modified"
+
+	// Modify the values returned by the accessors.
+	mutateParams(&rddnet.Params{
+		GenesisBlock: frozen.GenesisBlock(),
+		GenesisHash:  frozen.GenesisHash(),
+		PowLimit:     frozen.PowLimit(),
+		Checkpoints:  frozen.Checkpoints(),
+	})
+	mutateParams(frozen.Params())
+
+	if frozen.Name() != rddnet.MainNetParams.Name {
+		t.Errorf("frozen name modified: got %s", frozen.Name())
+	}
+	if frozen.Net() != rddnet.MainNetParams.Net {
+		t.Errorf("frozen net modified: got %v", frozen.Net())
+	}
+	if !reflect.DeepEqual(frozen.Params(), &rddnet.MainNetParams) {
+		t.Errorf("frozen parameters modified")
+	}
+	got := serializedGenesis(t, &rddnet.Params{
+		Name:         "frozen",
+		GenesisBlock: frozen.GenesisBlock(),
+	})
+	if !bytes.Equal(got, genesis) {
+		t.Errorf("frozen genesis block modified")
+	}
+}
+
+// TestFrozenParamsAccessors ensures FrozenParams has an accessor for every
+// field of Params which returns the value of the field, and that modifying the
+// slices and maps returned by the accessors does not modify the frozen
+// parameters.
+func TestFrozenParamsAccessors(t *testing.T) {
+	params := rddnet.MainNetParams.Clone()
+	params.Aliases = []string{"alias"}
+	params.CheckpointPubKeys = [][]byte{{0x02, 0x01}}
+	params.SignetChallenge = []byte{0x51}
+	frozen := params.Freeze()
+
+	// RelayNonStdTxs is a deprecated mirror of Policy.RelayNonStdTxs.
+	skip := map[string]bool{"RelayNonStdTxs": true}
+
+	paramsValue := reflect.ValueOf(params).Elem()
+	frozenValue := reflect.ValueOf(frozen)
+	for i := 0; i < paramsValue.NumField(); i++ {
+		name := paramsValue.Type().Field(i).Name
+		if skip[name] {
+			continue
+		}
+		method := frozenValue.MethodByName(name)
+		if !method.IsValid() {
+			t.Errorf("FrozenParams has no %s accessor", name)
+			continue
+		}
+		if method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+			t.Errorf("%s: accessor is not a getter", name)
+			continue
+		}
+		got := method.Call(nil)[0]
+		field := paramsValue.Field(i)
+		if !reflect.DeepEqual(got.Interface(), field.Interface()) {
+			t.Errorf("%s: got %v, want %v", name, got, field)
+			continue
+		}
+
+		switch got.Kind() {
+		case reflect.Slice:
+			if got.Len() == 0 {
+				t.Errorf("%s: test parameters have no values", name)
+				continue
+			}
+			got.Index(0).Set(reflect.Zero(got.Type().Elem()))
+		case reflect.Map:
+			for _, key := range got.MapKeys() {
+				got.SetMapIndex(key, reflect.Value{})
+			}
+		}
+	}
+
+	if !reflect.DeepEqual(frozen.Params(), params) {
+		t.Errorf("frozen parameters modified through accessors")
+	}
+}
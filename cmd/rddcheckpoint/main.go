@@ -0,0 +1,185 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+rddcheckpoint signs sync checkpoints with a checkpoint master key for test
+networks.  It prints the signed checkpoint serialized in the format it is
+relayed in as a hexadecimal string.
+
+Signing is refused for the main network since its master key must never be
+handled by a tool like this one.  Signing is deterministic, so signing the
+same block hash with the same key always prints the same checkpoint.
+
+Usage:
+
+	rddcheckpoint [flags] -hash blockhash
+
+The checkpoint master private key is read as a 32 byte hexadecimal scalar from
+the file named by -keyfile, or from standard input when the file is "-".
+Without -keyfile, it is read from the RDDCHECKPOINT_KEY environment variable.
+Keys are never accepted as arguments since those are visible to other users
+of the host and are kept in shell histories.
+
+The flags are:
+
+	-net name
+		The name or alias of the network the checkpoint is signed for
+		(default simnet).  When the network defines checkpoint master
+		public keys, the signed checkpoint must verify against them.
+	-keyfile path
+		The file holding the checkpoint master private key, or - for
+		standard input.
+	-hash blockhash
+		The hash of the checkpointed block.
+	-pubkey
+		Print the compressed public key of the master key instead of
+		signing.  Add it to the CheckpointPubKeys of the network.
+*/
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/reddcoin-project/rddec"
+	"github.com/reddcoin-project/rddnet"
+	"github.com/reddcoin-project/rddwire"
+)
+
+// keyEnv is the name of the environment variable the private key is read from
+// when no key file is passed.
+const keyEnv = "RDDCHECKPOINT_KEY"
+
+var (
+	// errMainNet describes an error where signing was requested for the
+	// main network.
+	errMainNet = errors.New("refusing to sign sync checkpoints for the " +
+		"main network")
+
+	// errInvalidKey describes an error where the private key is not a
+	// scalar in the range 1 to the order of the secp256k1 group.
+	errInvalidKey = errors.New("invalid private key: not a 32 byte " +
+		"scalar in the range of the curve order")
+
+	// errNoKey describes an error where neither a key file nor the key
+	// environment variable was given.
+	errNoKey = errors.New("no private key: pass -keyfile or set " + keyEnv)
+)
+
+// readKey returns the hex encoded private key read from the named file, from
+// stdin when the name is "-", or from the key environment variable looked up
+// with getenv when no file is named.  Surrounding white space is removed.
+func readKey(keyFile string, stdin io.Reader, getenv func(string) string) (string, error) {
+	var key string
+	switch keyFile {
+	case "":
+		key = getenv(keyEnv)
+	case "-":
+		b, err := ioutil.ReadAll(stdin)
+		if err != nil {
+			return "", err
+		}
+		key = string(b)
+	default:
+		b, err := ioutil.ReadFile(keyFile)
+		if err != nil {
+			return "", err
+		}
+		key = string(b)
+	}
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return "", errNoKey
+	}
+	return key, nil
+}
+
+// parsePrivKey returns the private key for the passed 32 byte scalar.  Unlike
+// rddec.PrivKeyFromBytes, scalars of other lengths and those which are zero or
+// not less than the curve order are rejected.
+func parsePrivKey(b []byte) (*rddec.PrivateKey, error) {
+	d := new(big.Int).SetBytes(b)
+	if len(b) != rddec.PrivKeyBytesLen || d.Sign() == 0 ||
+		d.Cmp(rddec.S256().N) >= 0 {
+
+		return nil, errInvalidKey
+	}
+	key, _ := rddec.PrivKeyFromBytes(rddec.S256(), b)
+	return key, nil
+}
+
+func main() {
+	net := flag.String("net", "simnet", "name of the network to sign "+
+		"the checkpoint for")
+	keyFile := flag.String("keyfile", "", "file holding the checkpoint "+
+		"master private key in hex, or - for stdin (default $"+keyEnv+")")
+	hash := flag.String("hash", "", "hash of the checkpointed block")
+	pubKey := flag.Bool("pubkey", false, "print the public key of the "+
+		"master key instead of signing")
+	flag.Parse()
+
+	if *hash == "" && !*pubKey {
+		flag.Usage()
+		os.Exit(2)
+	}
+	key, err := readKey(*keyFile, os.Stdin, os.Getenv)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "rddcheckpoint: %v\n", err)
+		os.Exit(1)
+	}
+	out, err := run(*net, key, *hash, *pubKey)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "rddcheckpoint: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(out)
+}
+
+// run signs a sync checkpoint of the passed block hash for the named network
+// with the hex encoded private key and returns it as a hex string.  When
+// pubKey is set, the hex encoded compressed public key of the private key is
+// returned instead.
+func run(netName, keyHex, hashStr string, pubKey bool) (string, error) {
+	params, err := rddnet.ParamsForName(netName)
+	if err != nil {
+		return "", fmt.Errorf("%s: %v", netName, err)
+	}
+	if params.Net == rddwire.MainNet {
+		return "", errMainNet
+	}
+
+	keyBytes, err := hex.DecodeString(keyHex)
+	if err != nil {
+		return "", fmt.Errorf("invalid private key: %v", err)
+	}
+	key, err := parsePrivKey(keyBytes)
+	if err != nil {
+		return "", err
+	}
+	if pubKey {
+		return hex.EncodeToString(key.PubKey().SerializeCompressed()), nil
+	}
+
+	hash, err := rddwire.NewShaHashFromStr(hashStr)
+	if err != nil {
+		return "", fmt.Errorf("invalid block hash: %v", err)
+	}
+	checkpoint, err := rddnet.SignCheckpoint(hash, key)
+	if err != nil {
+		return "", err
+	}
+	if len(params.CheckpointPubKeys) > 0 {
+		if err := params.VerifySignedCheckpoint(checkpoint); err != nil {
+			return "", fmt.Errorf("%s: %v", params.Name, err)
+		}
+	}
+	return hex.EncodeToString(checkpoint.Serialize()), nil
+}
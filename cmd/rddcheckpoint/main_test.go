@@ -0,0 +1,137 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/reddcoin-project/rddnet"
+)
+
+// testKey is the checkpoint master private key of the rddnet sync checkpoint
+// test vector.
+const testKey = "7ab1e8c7c3a0f5e19f8d2b6c4e3a1d0f9b8c7d6e5f4a3b2c1d0e9f8a7b6c5d4e"
+
+// TestRun ensures checkpoints are signed for test networks as in the rddnet
+// test vector and that signing for the main network is refused.
+func TestRun(t *testing.T) {
+	genesisHash := rddnet.SimNetParams.GenesisHash.String()
+
+	pubKey, err := run("sim", testKey, "", true)
+	if err != nil {
+		t.Fatalf("run -pubkey: unexpected error %v", err)
+	}
+	want := "022d8ec3e113b78c2e1fb1696664849fa7330193aff35bff41165f73a1f035471d"
+	if pubKey != want {
+		t.Errorf("run -pubkey: got %s want %s", pubKey, want)
+	}
+
+	out, err := run("simnet", testKey, genesisHash, false)
+	if err != nil {
+		t.Fatalf("run: unexpected error %v", err)
+	}
+	want = "2401000000f67ad7695d9b662a72ff3d8edbbb2de0bfa67b13974bb9910d116d5cbd863e68" +
+		"46304402200f9a8e2ac2a80868b7be641a6efcdc3ec0776aa67dbbfe041d70e04e799bc58f" +
+		"0220367d0e3d70976b0dc19cfb5c2f1cffacaf1d94178768b30be7021b7efcec8d1a"
+	if out != want {
+		t.Errorf("run: got %s want %s", out, want)
+	}
+
+	// The printed checkpoint verifies against the public key.
+	params := rddnet.SimNetParams.Clone()
+	key, _ := hex.DecodeString(pubKey)
+	params.CheckpointPubKeys = [][]byte{key}
+	b, _ := hex.DecodeString(out)
+	if _, err := params.VerifySyncCheckpoint(b); err != nil {
+		t.Errorf("VerifySyncCheckpoint: unexpected error %v", err)
+	}
+
+	tests := []struct {
+		name string
+		net  string
+		key  string
+		hash string
+	}{
+		{"main network", "mainnet", testKey, genesisHash},
+		{"unknown network", "nonet", testKey, genesisHash},
+		{"bad key", "simnet", "zz", genesisHash},
+		{"short key", "simnet", "00", genesisHash},
+		{"zero key", "simnet", strings.Repeat("00", 32), genesisHash},
+		{"key not below the curve order", "simnet",
+			strings.Repeat("ff", 32), genesisHash},
+		{"bad hash", "simnet", testKey, "zz"},
+	}
+	for _, test := range tests {
+		if _, err := run(test.net, test.key, test.hash, false); err == nil {
+			t.Errorf("%s: run succeeded", test.name)
+		}
+	}
+	if _, err := run("mainnet", testKey, genesisHash, false); err != errMainNet {
+		t.Errorf("main network: got error %v want %v", err, errMainNet)
+	}
+}
+
+// TestReadKey ensures private keys are read from files, stdin, and the
+// environment with surrounding white space removed.
+func TestReadKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rddcheckpoint")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	keyFile := filepath.Join(dir, "key")
+	if err := ioutil.WriteFile(keyFile, []byte(testKey+"\n"), 0600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	env := func(key string) string {
+		if key == keyEnv {
+			return " " + testKey
+		}
+		return ""
+	}
+	noEnv := func(string) string { return "" }
+
+	tests := []struct {
+		name    string
+		keyFile string
+		stdin   string
+		getenv  func(string) string
+		err     bool
+	}{
+		{"file", keyFile, "", noEnv, false},
+		{"stdin", "-", testKey + "\r\n", noEnv, false},
+		{"environment", "", "", env, false},
+		{"file before environment", keyFile, "", env, false},
+		{"missing file", filepath.Join(dir, "none"), "", env, true},
+		{"empty stdin", "-", "", env, true},
+		{"no key", "", testKey, noEnv, true},
+	}
+	for _, test := range tests {
+		key, err := readKey(test.keyFile, strings.NewReader(test.stdin),
+			test.getenv)
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: readKey succeeded", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		if key != testKey {
+			t.Errorf("%s: got key %q want %q", test.name, key, testKey)
+		}
+	}
+	if _, err := readKey("", nil, noEnv); err != errNoKey {
+		t.Errorf("no key: got error %v want %v", err, errNoKey)
+	}
+}
@@ -0,0 +1,443 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/reddcoin-project/rddec"
+	"github.com/reddcoin-project/rddnet"
+	"github.com/reddcoin-project/rddnet/merkle"
+	"github.com/reddcoin-project/rddwire"
+)
+
+// hexUint32 is a uint32 which may be given in JSON either as a number or as a
+// string holding a decimal or 0x prefixed hexadecimal number.  This allows
+// magics and difficulty bits to be written the way they usually are.
+type hexUint32 uint32
+
+// UnmarshalJSON satisfies the json.Unmarshaler interface.
+func (h *hexUint32) UnmarshalJSON(b []byte) error {
+	s := string(b)
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	v, err := strconv.ParseUint(s, 0, 32)
+	if err != nil {
+		return fmt.Errorf("invalid uint32 %s: %v", b, err)
+	}
+	*h = hexUint32(v)
+	return nil
+}
+
+// hexBytes is a byte slice given in JSON as a hexadecimal string.
+type hexBytes []byte
+
+// UnmarshalJSON satisfies the json.Unmarshaler interface.
+func (h *hexBytes) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	decoded, err := hex.DecodeString(s)
+	if err != nil {
+		return fmt.Errorf("invalid hex string %q: %v", s, err)
+	}
+	*h = decoded
+	return nil
+}
+
+// outputDesc describes an output of the genesis coinbase transaction.
+type outputDesc struct {
+	Value    int64    `json:"value"`
+	PkScript hexBytes `json:"pkScript"`
+}
+
+// genesisDesc describes the genesis block of a network.  It holds a single
+// coinbase transaction like the genesis blocks of the standard networks.  When
+// Nonce is omitted, a nonce which satisfies Bits is searched for.
+type genesisDesc struct {
+	Version   int32      `json:"version"`
+	Timestamp int64      `json:"timestamp"`
+	Bits      hexUint32  `json:"bits"`
+	Nonce     *hexUint32 `json:"nonce"`
+
+	TxVersion       int32        `json:"txVersion"`
+	TxTimestamp     *int64       `json:"txTimestamp"`
+	SignatureScript hexBytes     `json:"signatureScript"`
+	Outputs         []outputDesc `json:"outputs"`
+	LockTime        uint32       `json:"lockTime"`
+}
+
+// checkpointDesc describes a checkpoint of a network.
+type checkpointDesc struct {
+	Height int64  `json:"height"`
+	Hash   string `json:"hash"`
+}
+
+// headerSnapshotDesc describes a header snapshot of a network.  Header is the
+// 80 byte serialized block header and Work is the cumulative work of the chain
+// up to and including the block as a hexadecimal number.
+type headerSnapshotDesc struct {
+	Height int64    `json:"height"`
+	Header hexBytes `json:"header"`
+	Work   string   `json:"work"`
+
+	// header and work are the parsed Header and Work.
+	header rddwire.BlockHeader
+	work   *big.Int
+}
+
+// blockLimitsDesc describes the block limits of a network starting at a
+// height.
+type blockLimitsDesc struct {
+	Height    int64  `json:"height"`
+	MaxSize   uint32 `json:"maxSize"`
+	MaxSigOps uint32 `json:"maxSigOps"`
+	MaxWeight uint32 `json:"maxWeight"`
+}
+
+// policyDesc describes the default relay and mining policy of a network.
+type policyDesc struct {
+	RelayNonStdTxs      bool   `json:"relayNonStdTxs"`
+	MinRelayTxFee       int64  `json:"minRelayTxFee"`
+	DustThreshold       int64  `json:"dustThreshold"`
+	MaxStandardTxSize   uint32 `json:"maxStandardTxSize"`
+	MaxDataCarrierSize  uint32 `json:"maxDataCarrierSize"`
+	EnableRBF           bool   `json:"enableRBF"`
+	DefaultBlockMinSize uint32 `json:"defaultBlockMinSize"`
+}
+
+// networkDesc is the JSON description of a network the generator emits Go
+// source for.  Its fields mirror those of rddnet.Params.
+type networkDesc struct {
+	// VarName is the lowerCamelCase prefix of the generated unexported
+	// variables, such as devNet for devNetGenesisBlock.  The exported
+	// parameters variable is named after it as well, such as
+	// DevNetParams.
+	VarName string `json:"varName"`
+
+	Name        string    `json:"name"`
+	Net         hexUint32 `json:"net"`
+	DefaultPort string    `json:"defaultPort"`
+	RPCPort     uint16    `json:"rpcPort"`
+	WSPort      uint16    `json:"wsPort"`
+	Aliases     []string  `json:"aliases"`
+
+	Genesis                genesisDesc `json:"genesis"`
+	PowLimit               string      `json:"powLimit"`
+	PowLimitBits           hexUint32   `json:"powLimitBits"`
+	SubsidyHalvingInterval int32       `json:"subsidyHalvingInterval"`
+	ResetMinDifficulty     bool        `json:"resetMinDifficulty"`
+	PoSVTxVersion          int32       `json:"posvTxVersion"`
+	CoinbaseMaturity       uint16      `json:"coinbaseMaturity"`
+	CoinstakeMaturity      uint16      `json:"coinstakeMaturity"`
+
+	// MaxMoney defaults to rddnet.MaxSatoshi when omitted.
+	MaxMoney int64 `json:"maxMoney"`
+
+	BlockLimits []blockLimitsDesc `json:"blockLimits"`
+	Checkpoints []checkpointDesc  `json:"checkpoints"`
+
+	// HeaderSnapshots must be taken at the genesis block or at
+	// checkpoints.
+	HeaderSnapshots []headerSnapshotDesc `json:"headerSnapshots"`
+
+	// CheckpointPubKeys are serialized secp256k1 public keys.
+	CheckpointPubKeys []hexBytes `json:"checkpointPubKeys"`
+
+	// SignetChallenge is the challenge script of a signet.
+	SignetChallenge hexBytes `json:"signetChallenge"`
+
+	BlockV1RejectNumRequired       uint64 `json:"blockV1RejectNumRequired"`
+	BlockV1RejectNumToCheck        uint64 `json:"blockV1RejectNumToCheck"`
+	CoinbaseBlockHeightNumRequired uint64 `json:"coinbaseBlockHeightNumRequired"`
+	CoinbaseBlockHeightNumToCheck  uint64 `json:"coinbaseBlockHeightNumToCheck"`
+
+	// FeatureVersions maps the names of rddnet.ProtocolFeature constants,
+	// such as FeatureSendHeaders, to the protocol versions they are gated
+	// on.
+	MinProtocolVersion uint32            `json:"minProtocolVersion"`
+	FeatureVersions    map[string]uint32 `json:"featureVersions"`
+
+	Policy policyDesc `json:"policy"`
+
+	PubKeyHashAddrID hexUint32 `json:"pubKeyHashAddrID"`
+	ScriptHashAddrID hexUint32 `json:"scriptHashAddrID"`
+	PrivateKeyID     hexUint32 `json:"privateKeyID"`
+	Bech32HRPSegwit  string    `json:"bech32HRPSegwit"`
+
+	HDPrivateKeyID hexBytes `json:"hdPrivateKeyID"`
+	HDPublicKeyID  hexBytes `json:"hdPublicKeyID"`
+	HDCoinType     uint32   `json:"hdCoinType"`
+
+	// P2PPort is the parsed DefaultPort.
+	P2PPort uint16 `json:"-"`
+
+	// powLimit is the parsed PowLimit.
+	powLimit *big.Int
+}
+
+// protocolFeatures is the set of rddnet.ProtocolFeature constant names which
+// may be used as keys of the featureVersions description field.
+var protocolFeatures = map[string]struct{}{
+	"FeatureSendHeaders":   {},
+	"FeatureFeeFilter":     {},
+	"FeatureCompactBlocks": {},
+}
+
+// loadNetworkDesc reads and validates a JSON network description.
+func loadNetworkDesc(r io.Reader) (*networkDesc, error) {
+	var desc networkDesc
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&desc); err != nil {
+		return nil, err
+	}
+	if err := desc.validate(); err != nil {
+		return nil, err
+	}
+	return &desc, nil
+}
+
+// validate ensures the description can be turned into valid Go source.
+func (d *networkDesc) validate() error {
+	if d.VarName == "" || !isIdentifier(d.VarName) ||
+		strings.ToLower(d.VarName[:1]) != d.VarName[:1] {
+
+		return fmt.Errorf("varName %q is not a lowerCamelCase "+
+			"identifier", d.VarName)
+	}
+	if d.Name == "" {
+		return errors.New("name is required")
+	}
+	// The name is also written into comments of the generated source, so
+	// it must not contain line breaks or other control characters.
+	if !utf8.ValidString(d.Name) {
+		return fmt.Errorf("name %q is not valid UTF-8", d.Name)
+	}
+	for _, r := range d.Name {
+		if !unicode.IsPrint(r) {
+			return fmt.Errorf("name %q contains the unprintable "+
+				"character %U", d.Name, r)
+		}
+	}
+
+	port, err := strconv.ParseUint(d.DefaultPort, 10, 16)
+	if err != nil || port == 0 {
+		return fmt.Errorf("defaultPort %q is not a port", d.DefaultPort)
+	}
+	d.P2PPort = uint16(port)
+	if d.RPCPort == 0 {
+		return errors.New("rpcPort is required")
+	}
+	ports := rddnet.Params{DefaultPort: d.DefaultPort, P2PPort: d.P2PPort,
+		RPCPort: d.RPCPort, WSPort: d.WSPort}
+	if err := ports.VerifyPorts(); err != nil {
+		return err
+	}
+
+	powLimit, ok := new(big.Int).SetString(strings.TrimPrefix(d.PowLimit,
+		"0x"), 16)
+	if !ok || powLimit.Sign() <= 0 {
+		return fmt.Errorf("powLimit %q is not a positive hexadecimal "+
+			"number", d.PowLimit)
+	}
+	d.powLimit = powLimit
+
+	if d.Policy.MinRelayTxFee < 0 || d.Policy.DustThreshold < 0 {
+		return errors.New("policy fees and dust threshold must not be " +
+			"negative")
+	}
+	if d.MaxMoney < 0 {
+		return fmt.Errorf("maxMoney %d is negative", d.MaxMoney)
+	}
+
+	for _, id := range []hexUint32{d.PubKeyHashAddrID, d.ScriptHashAddrID,
+		d.PrivateKeyID} {
+
+		if id > 0xff {
+			return fmt.Errorf("address magic %#x is not a byte", id)
+		}
+	}
+	if len(d.HDPrivateKeyID) != 4 || len(d.HDPublicKeyID) != 4 {
+		return errors.New("hdPrivateKeyID and hdPublicKeyID must be " +
+			"4 bytes")
+	}
+
+	hrp := rddnet.Params{Bech32HRPSegwit: d.Bech32HRPSegwit}
+	if err := hrp.VerifyBech32HRP(); err != nil {
+		return fmt.Errorf("bech32HRPSegwit %q: %v", d.Bech32HRPSegwit,
+			err)
+	}
+
+	for feature := range d.FeatureVersions {
+		if _, ok := protocolFeatures[feature]; !ok {
+			return fmt.Errorf("featureVersions: unknown protocol "+
+				"feature %q", feature)
+		}
+	}
+
+	params := rddnet.Params{PoSVTxVersion: d.PoSVTxVersion}
+	posv := params.TxFormat(d.Genesis.TxVersion) == rddnet.TxFormatPoSV
+	if posv && d.Genesis.TxTimestamp == nil {
+		return errors.New("genesis txTimestamp is required for PoSV " +
+			"transactions")
+	}
+	if !posv && d.Genesis.TxTimestamp != nil {
+		return fmt.Errorf("genesis txTimestamp is not serialized by "+
+			"version %d transactions (posvTxVersion %d)",
+			d.Genesis.TxVersion, d.PoSVTxVersion)
+	}
+
+	if len(d.Genesis.Outputs) == 0 {
+		return errors.New("genesis coinbase requires at least one output")
+	}
+	for i, limits := range d.BlockLimits {
+		if i > 0 && limits.Height <= d.BlockLimits[i-1].Height {
+			return fmt.Errorf("block limits %d: block limits must "+
+				"be ordered from oldest to newest", i)
+		}
+	}
+	for i, checkpoint := range d.Checkpoints {
+		if _, err := rddwire.NewShaHashFromStr(checkpoint.Hash); err != nil {
+			return fmt.Errorf("checkpoint %d: %v", i, err)
+		}
+		if i > 0 && checkpoint.Height <= d.Checkpoints[i-1].Height {
+			return fmt.Errorf("checkpoint %d: checkpoints must be "+
+				"ordered from oldest to newest", i)
+		}
+	}
+	for i := range d.HeaderSnapshots {
+		snapshot := &d.HeaderSnapshots[i]
+		if len(snapshot.Header) != blockHeaderLen {
+			return fmt.Errorf("header snapshot %d: header must be "+
+				"%d bytes", i, blockHeaderLen)
+		}
+		err := snapshot.header.Deserialize(bytes.NewReader(snapshot.Header))
+		if err != nil {
+			return fmt.Errorf("header snapshot %d: %v", i, err)
+		}
+		work, ok := new(big.Int).SetString(strings.TrimPrefix(
+			snapshot.Work, "0x"), 16)
+		if !ok || work.Sign() <= 0 {
+			return fmt.Errorf("header snapshot %d: work %q is not a "+
+				"positive hexadecimal number", i, snapshot.Work)
+		}
+		snapshot.work = work
+	}
+	for i, key := range d.CheckpointPubKeys {
+		if _, err := rddec.ParsePubKey(key, rddec.S256()); err != nil {
+			return fmt.Errorf("checkpoint public key %d: %v", i, err)
+		}
+	}
+	if len(d.SignetChallenge) > 0 {
+		if _, err := rddnet.SigNetParams(d.SignetChallenge); err != nil {
+			return fmt.Errorf("signetChallenge: %v", err)
+		}
+	}
+	return nil
+}
+
+// blockHeaderLen is the length of a serialized block header.
+const blockHeaderLen = 80
+
+// isIdentifier returns whether s is a valid ASCII Go identifier.
+func isIdentifier(s string) bool {
+	for i, c := range s {
+		switch {
+		case c == '_', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return s != ""
+}
+
+// shaHashToBig converts a rddwire.ShaHash into a big.Int that can be used to
+// perform math comparisons.
+func shaHashToBig(hash *rddwire.ShaHash) *big.Int {
+	// A ShaHash is in little-endian, but the big package wants the bytes
+	// in big-endian, so reverse them.
+	buf := *hash
+	blen := len(buf)
+	for i := 0; i < blen/2; i++ {
+		buf[i], buf[blen-1-i] = buf[blen-1-i], buf[i]
+	}
+	return new(big.Int).SetBytes(buf[:])
+}
+
+// buildGenesis creates the genesis block described by d.  When the nonce is
+// not specified, nonces are tried in order until the block hash satisfies the
+// difficulty bits, which is only practical for low difficulty networks.
+func (d *networkDesc) buildGenesis() (*rddwire.MsgBlock, error) {
+	g := &d.Genesis
+	tx := rddwire.MsgTx{
+		Version: g.TxVersion,
+		TxIn: []*rddwire.TxIn{
+			{
+				PreviousOutPoint: rddwire.OutPoint{
+					Hash:  rddwire.ShaHash{},
+					Index: 0xffffffff,
+				},
+				SignatureScript: g.SignatureScript,
+				Sequence:        0xffffffff,
+			},
+		},
+		LockTime: g.LockTime,
+	}
+	for _, out := range g.Outputs {
+		tx.TxOut = append(tx.TxOut, &rddwire.TxOut{
+			Value:    out.Value,
+			PkScript: out.PkScript,
+		})
+	}
+	if g.TxTimestamp != nil {
+		tx.Timestamp = time.Unix(*g.TxTimestamp, 0)
+	}
+
+	// Hash the transaction in the format of the network rather than the
+	// one rddwire assumes for its version.
+	params := rddnet.Params{PoSVTxVersion: d.PoSVTxVersion}
+	merkleRoot := merkle.Root([]rddwire.ShaHash{params.TxSha(&tx)})
+	block := &rddwire.MsgBlock{
+		Header: rddwire.BlockHeader{
+			Version:    g.Version,
+			MerkleRoot: merkleRoot,
+			Timestamp:  time.Unix(g.Timestamp, 0),
+			Bits:       uint32(g.Bits),
+		},
+		Transactions: []*rddwire.MsgTx{&tx},
+	}
+	if g.Nonce != nil {
+		block.Header.Nonce = uint32(*g.Nonce)
+		return block, nil
+	}
+
+	target := rddnet.CompactToBig(block.Header.Bits)
+	for nonce := uint64(0); nonce <= 0xffffffff; nonce++ {
+		block.Header.Nonce = uint32(nonce)
+		hash, err := block.BlockSha()
+		if err != nil {
+			return nil, err
+		}
+		if shaHashToBig(&hash).Cmp(target) <= 0 {
+			return block, nil
+		}
+	}
+	return nil, fmt.Errorf("no nonce satisfies bits %08x", block.Header.Bits)
+}
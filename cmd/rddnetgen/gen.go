@@ -0,0 +1,490 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"math/big"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/reddcoin-project/rddnet"
+	"github.com/reddcoin-project/rddwire"
+)
+
+// genConfig holds the options which control how the source is generated.
+type genConfig struct {
+	// Package is the name of the package the source is generated for.
+	// When it is rddnet, the source refers to the package internals
+	// directly.  Otherwise it imports rddnet.
+	Package string
+
+	// Source is the name of the description file recorded in the header
+	// of the generated files.
+	Source string
+
+	// Helpers controls whether the newShaHashFromStr helper is emitted
+	// when generating for a package other than rddnet.  Only one network
+	// per package may emit it.
+	Helpers bool
+}
+
+// templateData is the data the source templates are executed with.
+type templateData struct {
+	*networkDesc
+	genConfig
+
+	Internal   bool
+	Qualifier  string
+	ParamsVar  string
+	TestName   string
+	Block      *rddwire.MsgBlock
+	Tx         *rddwire.MsgTx
+	BlockHash  rddwire.ShaHash
+	BlockBytes []byte
+}
+
+// byteLines formats b as the lines of a Go byte slice literal with eight bytes
+// per line.  When ascii is set, each line is followed by a comment showing the
+// printable characters of the line.
+func byteLines(b []byte, indent int, ascii bool) string {
+	var buf bytes.Buffer
+	tabs := strings.Repeat("\t", indent)
+	for len(b) > 0 {
+		n := 8
+		if len(b) < n {
+			n = len(b)
+		}
+		line := b[:n]
+		b = b[n:]
+
+		buf.WriteString(tabs)
+		for i, c := range line {
+			if i > 0 {
+				buf.WriteByte(' ')
+			}
+			fmt.Fprintf(&buf, "0x%02x,", c)
+		}
+		if ascii {
+			buf.WriteString(" /* |")
+			for _, c := range line {
+				if c < 32 || c > 126 {
+					c = '.'
+				}
+				buf.WriteByte(c)
+			}
+			buf.WriteString("| */")
+		}
+		if len(b) > 0 {
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.String()
+}
+
+// hashLines formats the hash as the lines of a Go byte array literal.
+func hashLines(hash rddwire.ShaHash) string {
+	return byteLines(hash[:], 1, false)
+}
+
+// powLimitExpr returns the Go expression for the proof of work limit along with
+// a description of it.  Limits of the form 2^n - 1, which all of the standard
+// networks use, are written the way params.go writes them.
+func powLimitExpr(n *big.Int, internal bool) (string, string) {
+	one := "big.NewInt(1)"
+	if internal {
+		one = "bigOne"
+	}
+	plusOne := new(big.Int).Add(n, big.NewInt(1))
+	bits := plusOne.BitLen() - 1
+	if new(big.Int).Lsh(big.NewInt(1), uint(bits)).Cmp(plusOne) == 0 {
+		return fmt.Sprintf("new(big.Int).Sub(new(big.Int).Lsh(%s, %d), %s)",
+			one, bits, one), fmt.Sprintf("2^%d - 1", bits)
+	}
+	return fmt.Sprintf("func() *big.Int { n, _ := new(big.Int).SetString(%q, "+
+		"16); return n }()", n.Text(16)), fmt.Sprintf("0x%x", n)
+}
+
+// templateFuncs are the functions available to the source templates.
+var templateFuncs = template.FuncMap{
+	"bytes": byteLines,
+	"hash":  hashLines,
+	"utc": func(t time.Time) string {
+		return t.UTC().String()
+	},
+	"lower": strings.ToLower,
+}
+
+// sourceTemplate is the template of the generated parameters source.
+var sourceTemplate = template.Must(template.New("source").Funcs(
+	templateFuncs).Parse(`// Code generated by rddnetgen from {{.Source}}.  DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"math/big"
+	"time"
+{{if not .Internal}}
+	"github.com/reddcoin-project/rddnet"{{end}}
+	"github.com/reddcoin-project/rddwire"
+)
+
+// {{.VarName}}PowLimit is the highest proof of work value a block can have for
+// the {{.Name}} network.  It is the value {{.PowLimitDesc}}.
+var {{.VarName}}PowLimit = {{.PowLimitExpr}}
+
+// {{.VarName}}GenesisCoinbaseTx is the coinbase transaction for the genesis
+// block for the {{.Name}} network.
+var {{.VarName}}GenesisCoinbaseTx = rddwire.MsgTx{
+	Version: {{.Tx.Version}},
+	TxIn: []*rddwire.TxIn{
+		{
+			PreviousOutPoint: rddwire.OutPoint{
+				Hash:  rddwire.ShaHash{},
+				Index: 0xffffffff,
+			},
+			SignatureScript: []byte{
+{{bytes (index .Tx.TxIn 0).SignatureScript 4 true}}
+			},
+			Sequence: 0xffffffff,
+		},
+	},
+	TxOut: []*rddwire.TxOut{ {{- range .Tx.TxOut}}
+		{
+			Value: {{printf "%#x" .Value}}, // {{.Value}}
+			PkScript: []byte{
+{{bytes .PkScript 4 true}}
+			},
+		},{{end}}
+	},
+	LockTime: {{.Tx.LockTime}},{{if .Genesis.TxTimestamp}}
+	Timestamp: time.Unix({{.Tx.Timestamp.Unix}}, 0), // {{utc .Tx.Timestamp}}{{end}}
+}
+
+// {{.VarName}}GenesisHash is the hash of the first block in the block chain for
+// the {{.Name}} network (genesis block).
+var {{.VarName}}GenesisHash = rddwire.ShaHash([rddwire.HashSize]byte{ // Make go vet happy.
+{{hash .BlockHash}}
+})
+
+// {{.VarName}}GenesisMerkleRoot is the hash of the first transaction in the
+// genesis block for the {{.Name}} network.
+var {{.VarName}}GenesisMerkleRoot = rddwire.ShaHash([rddwire.HashSize]byte{ // Make go vet happy.
+{{hash .Block.Header.MerkleRoot}}
+})
+
+// {{.VarName}}GenesisBlock defines the genesis block of the block chain which
+// serves as the public transaction ledger for the {{.Name}} network.
+var {{.VarName}}GenesisBlock = rddwire.MsgBlock{
+	Header: rddwire.BlockHeader{
+		Version:    {{.Block.Header.Version}},
+		PrevBlock:  rddwire.ShaHash{}, // {{.Block.Header.PrevBlock}}
+		MerkleRoot: {{.VarName}}GenesisMerkleRoot, // {{.Block.Header.MerkleRoot}}
+		Timestamp:  time.Unix({{.Block.Header.Timestamp.Unix}}, 0), // {{utc .Block.Header.Timestamp}}
+		Bits:       {{printf "%#08x" .Block.Header.Bits}}, // {{.Block.Header.Bits}}
+		Nonce:      {{printf "%#08x" .Block.Header.Nonce}}, // {{.Block.Header.Nonce}}
+	},
+	Transactions: []*rddwire.MsgTx{&{{.VarName}}GenesisCoinbaseTx},
+}
+
+// {{.ParamsVar}} defines the network parameters for the {{.Name}} network.
+var {{.ParamsVar}} = {{.Qualifier}}Params{
+	Name:        {{printf "%q" .Name}},
+	Net:         {{printf "%#08x" .Net}},
+	DefaultPort: "{{.DefaultPort}}",
+	P2PPort:     {{.P2PPort}},
+	RPCPort:     {{.RPCPort}},
+{{- if .WSPort}}
+	WSPort:      {{.WSPort}},
+{{- end}}
+{{- if .Aliases}}
+	Aliases:     []string{ {{- range $i, $alias := .Aliases}}{{if $i}}, {{end}}{{printf "%q" $alias}}{{end -}} },
+{{- end}}
+
+	// Chain parameters
+	GenesisBlock:           &{{.VarName}}GenesisBlock,
+	GenesisHash:            &{{.VarName}}GenesisHash,
+	PowLimit:               {{.VarName}}PowLimit,
+	PowLimitBits:           {{printf "%#08x" .PowLimitBits}},
+	SubsidyHalvingInterval: {{.SubsidyHalvingInterval}},
+	ResetMinDifficulty:     {{.ResetMinDifficulty}},
+	PoSVTxVersion:          {{.PoSVTxVersion}},
+	CoinbaseMaturity:       {{.CoinbaseMaturity}},
+	CoinstakeMaturity:      {{.CoinstakeMaturity}},
+	MaxMoney:               {{if .MaxMoney}}{{.MaxMoney}}{{else}}{{.Qualifier}}MaxSatoshi{{end}},
+
+	// Block size and cost limits ordered by activation height.
+	BlockLimits: {{if .BlockLimits}}[]{{.Qualifier}}BlockLimits{ {{- range .BlockLimits}}
+		{Height: {{.Height}}, MaxSize: {{.MaxSize}}, MaxSigOps: {{.MaxSigOps}}, MaxWeight: {{.MaxWeight}}},{{end}}
+	},{{else}}nil,{{end}}
+
+	// Checkpoints ordered from oldest to newest.
+	Checkpoints: {{if .Checkpoints}}[]{{.Qualifier}}Checkpoint{ {{- range .Checkpoints}}
+		{{if $.Internal}}{ {{- .Height}}, newShaHashFromStr("{{lower .Hash}}")},{{else}}{Height: {{.Height}}, Hash: newShaHashFromStr("{{lower .Hash}}")},{{end}}{{end}}
+	},{{else}}nil,{{end}}
+
+	// Headers of the genesis block or of checkpointed blocks ordered from
+	// oldest to newest.
+	HeaderSnapshots: {{if .HeaderSnapshots}}[]{{.Qualifier}}HeaderSnapshot{ {{- range .HeaderSnapshots}}
+		{
+			Height: {{.Height}},
+			Header: rddwire.BlockHeader{
+				Version:    {{.BlockHeader.Version}},
+				PrevBlock:  *newShaHashFromStr("{{.BlockHeader.PrevBlock}}"),
+				MerkleRoot: *newShaHashFromStr("{{.BlockHeader.MerkleRoot}}"),
+				Timestamp:  time.Unix({{.BlockHeader.Timestamp.Unix}}, 0), // {{utc .BlockHeader.Timestamp}}
+				Bits:       {{printf "%#08x" .BlockHeader.Bits}},
+				Nonce:      {{printf "%#08x" .BlockHeader.Nonce}},
+			},
+			Work: {{.WorkExpr}},
+		},{{end}}
+	},{{else}}nil,{{end}}
+
+	// Checkpoint master public keys allowed to sign sync checkpoints.
+	CheckpointPubKeys: {{if .CheckpointPubKeys}}[][]byte{ {{- range .CheckpointPubKeys}}
+		{
+{{bytes . 3 false}}
+		},{{end}}
+	},{{else}}nil,{{end}}
+
+	// Challenge the block solutions of a signet must satisfy.
+	SignetChallenge: {{if .SignetChallenge}}[]byte{
+{{bytes .SignetChallenge 2 false}}
+	},{{else}}nil,{{end}}
+
+	// Reject version 1 blocks once a majority of the network has upgraded.
+	// This is part of BIP0034.
+	BlockV1RejectNumRequired: {{.BlockV1RejectNumRequired}},
+	BlockV1RejectNumToCheck:  {{.BlockV1RejectNumToCheck}},
+
+	// Ensure coinbase starts with serialized block heights for version 2
+	// blocks or newer once a majority of the network has upgraded.
+	// This is part of BIP0034.
+	CoinbaseBlockHeightNumRequired: {{.CoinbaseBlockHeightNumRequired}},
+	CoinbaseBlockHeightNumToCheck:  {{.CoinbaseBlockHeightNumToCheck}},
+
+	// Peer-to-peer protocol parameters
+	MinProtocolVersion: {{.MinProtocolVersion}},
+	FeatureVersions: {{if .FeatureVersions}}map[{{.Qualifier}}ProtocolFeature]uint32{ {{- range $feature, $version := .FeatureVersions}}
+		{{$.Qualifier}}{{$feature}}: {{$version}},{{end}}
+	},{{else}}nil,{{end}}
+
+	// Default relay and mining policy
+	Policy: {{.Qualifier}}Policy{
+		RelayNonStdTxs:      {{.Policy.RelayNonStdTxs}},
+		MinRelayTxFee:       {{.Policy.MinRelayTxFee}},
+		DustThreshold:       {{.Policy.DustThreshold}},
+		MaxStandardTxSize:   {{.Policy.MaxStandardTxSize}},
+		MaxDataCarrierSize:  {{.Policy.MaxDataCarrierSize}},
+		EnableRBF:           {{.Policy.EnableRBF}},
+		DefaultBlockMinSize: {{.Policy.DefaultBlockMinSize}},
+	},
+
+	// Deprecated mirror of Policy.RelayNonStdTxs
+	RelayNonStdTxs: {{.Policy.RelayNonStdTxs}},
+
+	// Address encoding magics
+	PubKeyHashAddrID: {{printf "%#02x" .PubKeyHashAddrID}},
+	ScriptHashAddrID: {{printf "%#02x" .ScriptHashAddrID}},
+	PrivateKeyID:     {{printf "%#02x" .PrivateKeyID}},
+
+	// Human-readable part for Bech32 encoded segwit addresses, as defined in
+	// BIP0173.
+	Bech32HRPSegwit: {{printf "%q" .Bech32HRPSegwit}},
+
+	// BIP32 hierarchical deterministic extended key magics
+	HDPrivateKeyID: [4]byte{ {{- bytes .HDPrivateKeyID 0 false}}},
+	HDPublicKeyID:  [4]byte{ {{- bytes .HDPublicKeyID 0 false}}},
+
+	// BIP44 coin type used in the hierarchical deterministic path for
+	// address generation.
+	HDCoinType: {{.HDCoinType}},
+}
+{{if and (not .Internal) .Helpers}}
+// newShaHashFromStr converts the passed big-endian hex string into a
+// rddwire.ShaHash.  It only differs from the one available in rddwire in that
+// it panics on an error since it will only (and must only) be called with
+// hard-coded, and therefore known good, hashes.
+func newShaHashFromStr(hexStr string) *rddwire.ShaHash {
+	sha, err := rddwire.NewShaHashFromStr(hexStr)
+	if err != nil {
+		panic(err)
+	}
+	return sha
+}
+{{end}}`))
+
+// testTemplate is the template of the generated genesis block test.
+var testTemplate = template.Must(template.New("test").Funcs(
+	templateFuncs).Parse(`// Code generated by rddnetgen from {{.Source}}.  DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+// {{.TestName}} tests the genesis block of the {{.Name}} network for
+// validity by checking the encoded bytes and hashes.
+func {{.TestName}}(t *testing.T) {
+	// Encode the genesis block to raw bytes.
+	var buf bytes.Buffer
+	err := {{.ParamsVar}}.GenesisBlock.Serialize(&buf)
+	if err != nil {
+		t.Fatalf("{{.TestName}}: %v", err)
+	}
+
+	// Ensure the encoded block matches the expected bytes.
+	if !bytes.Equal(buf.Bytes(), {{.VarName}}GenesisBlockBytes) {
+		t.Fatalf("{{.TestName}}: Genesis block does not appear valid - "+
+			"got %v, want %v", spew.Sdump(buf.Bytes()),
+			spew.Sdump({{.VarName}}GenesisBlockBytes))
+	}
+
+	// Check hash of the block against expected hash.
+	hash, err := {{.ParamsVar}}.GenesisBlock.BlockSha()
+	if err != nil {
+		t.Fatalf("BlockSha: %v", err)
+	}
+	if !{{.ParamsVar}}.GenesisHash.IsEqual(&hash) {
+		t.Fatalf("{{.TestName}}: Genesis block hash does not "+
+			"appear valid - got %v, want %v", spew.Sdump(hash),
+			spew.Sdump({{.ParamsVar}}.GenesisHash))
+	}
+{{- if .HeaderSnapshots}}
+
+	// Ensure the header snapshots are consistent with the genesis block
+	// and the checkpoints.
+	if err := {{.ParamsVar}}.VerifyHeaderSnapshots(); err != nil {
+		t.Fatalf("{{.TestName}}: %v", err)
+	}
+{{- end}}
+}
+
+// {{.VarName}}GenesisBlockBytes are the wire encoded bytes for the genesis
+// block of the {{.Name}} network.
+var {{.VarName}}GenesisBlockBytes = []byte{
+{{bytes .BlockBytes 1 true}}
+}
+`))
+
+// generate returns the formatted Go source of the network parameters and of
+// the genesis block test for the passed description.
+func generate(desc *networkDesc, cfg genConfig) ([]byte, []byte, error) {
+	block, err := desc.buildGenesis()
+	if err != nil {
+		return nil, nil, err
+	}
+	hash, err := block.BlockSha()
+	if err != nil {
+		return nil, nil, err
+	}
+	var blockBytes bytes.Buffer
+	if err := block.Serialize(&blockBytes); err != nil {
+		return nil, nil, err
+	}
+
+	if err := verifyHeaderSnapshots(desc, &hash); err != nil {
+		return nil, nil, err
+	}
+
+	exported := strings.ToUpper(desc.VarName[:1]) + desc.VarName[1:]
+	data := templateData{
+		networkDesc: desc,
+		genConfig:   cfg,
+		Internal:    cfg.Package == "rddnet",
+		ParamsVar:   exported + "Params",
+		TestName:    "Test" + exported + "GenesisBlock",
+		Block:       block,
+		Tx:          block.Transactions[0],
+		BlockHash:   hash,
+		BlockBytes:  blockBytes.Bytes(),
+	}
+	if !data.Internal {
+		data.Qualifier = "rddnet."
+	}
+
+	src, err := execute(sourceTemplate, &data)
+	if err != nil {
+		return nil, nil, err
+	}
+	testSrc, err := execute(testTemplate, &data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return src, testSrc, nil
+}
+
+// verifyHeaderSnapshots checks the header snapshots of the description against
+// its checkpoints and the hash of its genesis block with
+// rddnet.Params.VerifyHeaderSnapshots so inconsistent snapshots are reported
+// before any source is generated.
+func verifyHeaderSnapshots(desc *networkDesc, genesisHash *rddwire.ShaHash) error {
+	params := rddnet.Params{GenesisHash: genesisHash}
+	for _, checkpoint := range desc.Checkpoints {
+		hash, err := rddwire.NewShaHashFromStr(checkpoint.Hash)
+		if err != nil {
+			return err
+		}
+		params.Checkpoints = append(params.Checkpoints,
+			rddnet.Checkpoint{Height: checkpoint.Height, Hash: hash})
+	}
+	for _, snapshot := range desc.HeaderSnapshots {
+		params.HeaderSnapshots = append(params.HeaderSnapshots,
+			rddnet.HeaderSnapshot{
+				Height: snapshot.Height,
+				Header: snapshot.header,
+				Work:   snapshot.work,
+			})
+	}
+	return params.VerifyHeaderSnapshots()
+}
+
+// PowLimitExpr returns the Go expression of the proof of work limit for use
+// by the source template.
+func (d *templateData) PowLimitExpr() string {
+	expr, _ := powLimitExpr(d.powLimit, d.Internal)
+	return expr
+}
+
+// PowLimitDesc returns the description of the proof of work limit for use by
+// the source template.
+func (d *templateData) PowLimitDesc() string {
+	_, desc := powLimitExpr(d.powLimit, d.Internal)
+	return desc
+}
+
+// BlockHeader returns the parsed header of the snapshot for use by the source
+// template.
+func (s headerSnapshotDesc) BlockHeader() rddwire.BlockHeader {
+	return s.header
+}
+
+// WorkExpr returns the Go expression of the cumulative work of the snapshot for
+// use by the source template.
+func (s headerSnapshotDesc) WorkExpr() string {
+	return fmt.Sprintf("func() *big.Int { n, _ := new(big.Int).SetString(%q, "+
+		"16); return n }()", s.work.Text(16))
+}
+
+// execute executes the template and formats the result with gofmt.
+func execute(tmpl *template.Template, data *templateData) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("generated invalid source: %v\n%s", err,
+			buf.Bytes())
+	}
+	return src, nil
+}
@@ -0,0 +1,350 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/reddcoin-project/rddnet"
+)
+
+// simNetDesc is a description of the simulation test network.
+const simNetDesc = `{
+	"varName": "simNet",
+	"name": "simnet",
+	"net": "0x12141c16",
+	"defaultPort": "18555",
+	"rpcPort": 18556,
+	"aliases": ["sim", "simulation"],
+	"genesis": {
+		"version": 1,
+		"timestamp": 1401292357,
+		"bits": "0x207fffff",
+		"nonce": 2,
+		"txVersion": 1,
+		"signatureScript": "04ffff001d0104455468652054696d65732030332f4a616e2f32303039204368616e63656c6c6f72206f6e206272696e6b206f66207365636f6e64206261696c6f757420666f722062616e6b73",
+		"outputs": [{
+			"value": 5000000000,
+			"pkScript": "4104678afdb0fe5548271967f1a67130b7105cd6a828e03909a67962e0ea1f61deb649f6bc3f4cef38c4f35504e51ec112de5c384df7ba0b8d578a4c702b6bf11d5fac"
+		}]
+	},
+	"powLimit": "7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+	"powLimitBits": "0x207fffff",
+	"resetMinDifficulty": true,
+	"posvTxVersion": 2,
+	"coinbaseMaturity": 50,
+	"coinstakeMaturity": 50,
+	"blockLimits": [
+		{"height": 0, "maxSize": 1000000, "maxSigOps": 20000},
+		{"height": 1000, "maxSize": 4000000, "maxSigOps": 80000, "maxWeight": 4000000}
+	],
+	"checkpoints": [
+		{"height": 1, "hash": "0000000000000000000000000000000000000000000000000000000000000001"}
+	],
+	"headerSnapshots": [{
+		"height": 0,
+		"header": "0100000000000000000000000000000000000000000000000000000000000000000000003ba3edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa4b1e5e4a45068653ffff7f2002000000",
+		"work": "2"
+	}],
+	"checkpointPubKeys": ["022d8ec3e113b78c2e1fb1696664849fa7330193aff35bff41165f73a1f035471d"],
+	"signetChallenge": "21022d8ec3e113b78c2e1fb1696664849fa7330193aff35bff41165f73a1f035471dac",
+	"blockV1RejectNumRequired": 75,
+	"blockV1RejectNumToCheck": 100,
+	"coinbaseBlockHeightNumRequired": 51,
+	"coinbaseBlockHeightNumToCheck": 100,
+	"minProtocolVersion": 70002,
+	"featureVersions": {"FeatureSendHeaders": 70012, "FeatureFeeFilter": 70013},
+	"policy": {
+		"relayNonStdTxs": true,
+		"minRelayTxFee": 1000,
+		"dustThreshold": 546,
+		"maxStandardTxSize": 100000,
+		"maxDataCarrierSize": 80
+	},
+	"pubKeyHashAddrID": "0x3f",
+	"scriptHashAddrID": "0x7b",
+	"privateKeyID": "0x64",
+	"bech32HRPSegwit": "srdd",
+	"hdPrivateKeyID": "0420b900",
+	"hdPublicKeyID": "0420bd3a",
+	"hdCoinType": 115
+}`
+
+// TestBuildGenesis ensures the genesis block built from a description of the
+// simulation test network matches the one in rddnet and that a nonce is found
+// when it is omitted.
+func TestBuildGenesis(t *testing.T) {
+	desc, err := loadNetworkDesc(strings.NewReader(simNetDesc))
+	if err != nil {
+		t.Fatalf("loadNetworkDesc: %v", err)
+	}
+	block, err := desc.buildGenesis()
+	if err != nil {
+		t.Fatalf("buildGenesis: %v", err)
+	}
+	hash, err := block.BlockSha()
+	if err != nil {
+		t.Fatalf("BlockSha: %v", err)
+	}
+	if !rddnet.SimNetParams.GenesisHash.IsEqual(&hash) {
+		t.Fatalf("genesis hash mismatch: got %v, want %v", hash,
+			rddnet.SimNetParams.GenesisHash)
+	}
+
+	desc.Genesis.Nonce = nil
+	block, err = desc.buildGenesis()
+	if err != nil {
+		t.Fatalf("buildGenesis without nonce: %v", err)
+	}
+	hash, err = block.BlockSha()
+	if err != nil {
+		t.Fatalf("BlockSha: %v", err)
+	}
+	if shaHashToBig(&hash).Cmp(rddnet.CompactToBig(block.Header.Bits)) > 0 {
+		t.Fatalf("mined genesis hash %v does not satisfy bits %08x",
+			hash, block.Header.Bits)
+	}
+}
+
+// TestGenerate ensures the generated source parses and is written in the style
+// of the standard networks for both rddnet and other packages.
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		cfg     genConfig
+		want    []string
+		notWant []string
+	}{
+		{
+			cfg: genConfig{Package: "rddnet", Source: "simnet.json",
+				Helpers: true},
+			want: []string{
+				"var SimNetParams = Params{",
+				"new(big.Int).Sub(new(big.Int).Lsh(bigOne, 255), bigOne)",
+				`{1, newShaHashFromStr("0000000000000000000000000000000000000000000000000000000000000001")},`,
+				"FeatureVersions: map[ProtocolFeature]uint32{",
+				"FeatureSendHeaders: 70012,",
+				"MaxMoney:               MaxSatoshi,",
+				"{Height: 1000, MaxSize: 4000000, MaxSigOps: 80000, MaxWeight: 4000000},",
+				"DustThreshold:       546,",
+				"Policy: Policy{",
+				"HeaderSnapshots: []HeaderSnapshot{",
+				`PrevBlock:  *newShaHashFromStr("0000000000000000000000000000000000000000000000000000000000000000"),`,
+			},
+			notWant: []string{
+				"func newShaHashFromStr",
+				`"github.com/reddcoin-project/rddnet"`,
+			},
+		},
+		{
+			cfg: genConfig{Package: "devnets", Source: "simnet.json",
+				Helpers: true},
+			want: []string{
+				"var SimNetParams = rddnet.Params{",
+				"big.NewInt(1), 255)",
+				`{Height: 1, Hash: newShaHashFromStr(`,
+				"func newShaHashFromStr",
+				"rddnet.FeatureFeeFilter:   70013,",
+				"MaxMoney:               rddnet.MaxSatoshi,",
+				"HeaderSnapshots: []rddnet.HeaderSnapshot{",
+			},
+		},
+		{
+			cfg: genConfig{Package: "devnets", Source: "simnet.json"},
+			notWant: []string{
+				"func newShaHashFromStr",
+			},
+		},
+	}
+
+	// Lines which must be present regardless of the package.
+	common := []string{
+		"// Code generated by rddnetgen from simnet.json.  DO NOT EDIT.",
+		"var simNetGenesisHash = rddwire.ShaHash([rddwire.HashSize]byte{ // Make go vet happy.",
+		"0xf6, 0x7a, 0xd7, 0x69, 0x5d, 0x9b, 0x66, 0x2a,",
+		"0x3b, 0xa3, 0xed, 0xfd, 0x7a, 0x7b, 0x12, 0xb2,",
+		"0x73, 0x20, 0x30, 0x33, 0x2f, 0x4a, 0x61, 0x6e, /* |s 03/Jan| */",
+		"Timestamp:  time.Unix(1401292357, 0), // 2014-05-28 15:52:37 +0000 UTC",
+		"HDPrivateKeyID: [4]byte{0x04, 0x20, 0xb9, 0x00},",
+		`MerkleRoot: *newShaHashFromStr("4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"),`,
+		`Work: func() *big.Int { n, _ := new(big.Int).SetString("2", 16); return n }(),`,
+		"CheckpointPubKeys: [][]byte{",
+		"0x02, 0x2d, 0x8e, 0xc3, 0xe1, 0x13, 0xb7, 0x8c,",
+		"SignetChallenge: []byte{",
+		"0x21, 0x02, 0x2d, 0x8e, 0xc3, 0xe1, 0x13, 0xb7,",
+		`Aliases:     []string{"sim", "simulation"},`,
+		"P2PPort:     18555,",
+		"RPCPort:     18556,",
+	}
+
+	for _, test := range tests {
+		desc, err := loadNetworkDesc(strings.NewReader(simNetDesc))
+		if err != nil {
+			t.Fatalf("loadNetworkDesc: %v", err)
+		}
+		src, testSrc, err := generate(desc, test.cfg)
+		if err != nil {
+			t.Fatalf("%s: generate: %v", test.cfg.Package, err)
+		}
+
+		fset := token.NewFileSet()
+		for name, b := range map[string][]byte{"source": src,
+			"test": testSrc} {
+
+			if _, err := parser.ParseFile(fset, name, b, 0); err != nil {
+				t.Errorf("%s: generated %s does not parse: %v",
+					test.cfg.Package, name, err)
+			}
+		}
+
+		for _, want := range append(common, test.want...) {
+			if !bytes.Contains(src, []byte(want)) {
+				t.Errorf("%s: generated source does not contain "+
+					"%q:\n%s", test.cfg.Package, want, src)
+			}
+		}
+		for _, notWant := range test.notWant {
+			if bytes.Contains(src, []byte(notWant)) {
+				t.Errorf("%s: generated source contains %q",
+					test.cfg.Package, notWant)
+			}
+		}
+		if !bytes.Contains(testSrc, []byte("func TestSimNetGenesisBlock(")) {
+			t.Errorf("%s: generated test does not define "+
+				"TestSimNetGenesisBlock", test.cfg.Package)
+		}
+		if !bytes.Contains(testSrc, []byte("SimNetParams.VerifyHeaderSnapshots()")) {
+			t.Errorf("%s: generated test does not verify the "+
+				"header snapshots", test.cfg.Package)
+		}
+	}
+}
+
+// TestGeneratedPackage ensures the source generated for a package other than
+// rddnet compiles, passes go vet, and passes its own genesis block test.  The
+// name and human-readable part hold quotes and backslashes to ensure they are
+// escaped.
+func TestGeneratedPackage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping go tool invocation in short mode")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go tool not found")
+	}
+
+	descJSON := strings.Replace(simNetDesc, `"name": "simnet"`,
+		`"name": "sim\"net\\"`, 1)
+	descJSON = strings.Replace(descJSON, `"bech32HRPSegwit": "srdd"`,
+		`"bech32HRPSegwit": "s\"rdd\\"`, 1)
+	desc, err := loadNetworkDesc(strings.NewReader(descJSON))
+	if err != nil {
+		t.Fatalf("loadNetworkDesc: %v", err)
+	}
+	src, testSrc, err := generate(desc, genConfig{Package: "devnets",
+		Source: "simnet.json", Helpers: true})
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	// The package is written inside this module so it resolves the same
+	// dependencies.  The leading underscore keeps it out of ./... patterns.
+	dir, err := ioutil.TempDir(".", "_generated")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string][]byte{"simnet.go": src, "simnet_test.go": testSrc}
+	for name, b := range files {
+		err := ioutil.WriteFile(filepath.Join(dir, name), b, 0644)
+		if err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	pkg := "./" + filepath.Base(dir)
+	for _, args := range [][]string{{"vet", pkg}, {"test", pkg}} {
+		out, err := exec.Command("go", args...).CombinedOutput()
+		if err != nil {
+			t.Errorf("go %s: %v\n%s\n%s", args[0], err, out, src)
+		}
+	}
+}
+
+// TestGenerateHeaderSnapshotErrors ensures header snapshots which do not match
+// the genesis block or a checkpoint are rejected before generating source.
+func TestGenerateHeaderSnapshotErrors(t *testing.T) {
+	desc, err := loadNetworkDesc(strings.NewReader(strings.Replace(
+		simNetDesc, "\"height\": 0,\n\t\t\"header\"",
+		"\"height\": 1,\n\t\t\"header\"", 1)))
+	if err != nil {
+		t.Fatalf("loadNetworkDesc: %v", err)
+	}
+	_, _, err = generate(desc, genConfig{Package: "rddnet"})
+	if _, ok := err.(rddnet.HeaderSnapshotError); !ok {
+		t.Fatalf("generate: got error %v, want HeaderSnapshotError", err)
+	}
+}
+
+// TestLoadNetworkDescErrors ensures invalid descriptions are rejected.
+func TestLoadNetworkDescErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		old     string
+		new     string
+		wantErr string
+	}{
+		{"bad var name", `"simNet"`, `"SimNet"`, "varName"},
+		{"unknown field", `"name"`, `"nmae"`, "unknown field"},
+		{"bad pow limit", `"7fff`, `"zfff`, "powLimit"},
+		{"control character in name", `"name": "simnet"`,
+			`"name": "sim\nnet"`, "unprintable"},
+		{"bad hrp", `"srdd"`, `"s rdd"`, "bech32HRPSegwit"},
+		{"mixed case hrp", `"srdd"`, `"sRdd"`, "bech32HRPSegwit"},
+		{"bad address magic", `"0x3f"`, `"0x13f"`, "not a byte"},
+		{"unknown feature", `"FeatureFeeFilter"`, `"FeatureBloom"`,
+			"protocol feature"},
+		{"negative max money", `"coinstakeMaturity": 50,`,
+			`"coinstakeMaturity": 50, "maxMoney": -1,`, "maxMoney"},
+		{"unordered block limits", `"height": 1000,`, `"height": 0,`,
+			"block limits 1"},
+		{"negative dust threshold", `"dustThreshold": 546`,
+			`"dustThreshold": -1`, "dust threshold"},
+		{"legacy tx timestamp", `"txVersion": 1,`,
+			`"txVersion": 1, "txTimestamp": 1401292357,`,
+			"not serialized"},
+		{"posv tx without timestamp", `"txVersion": 1,`,
+			`"txVersion": 2,`, "txTimestamp is required"},
+		{"bad hd magic", `"0420b900"`, `"0420b9"`, "4 bytes"},
+		{"bad checkpoint", `"00000000000000000000000000000000` +
+			`00000000000000000000000000000001"`, `"zz"`, "checkpoint"},
+		{"bad checkpoint public key", `"022d8ec3`, `"052d8ec3`,
+			"checkpoint public key 0"},
+		{"bad default port", `"18555"`, `"65536"`, "defaultPort"},
+		{"missing rpc port", `"rpcPort": 18556`, `"rpcPort": 0`, "rpcPort"},
+		{"shared ws port", `"rpcPort": 18556,`,
+			`"rpcPort": 18556, "wsPort": 18555,`, "WSPort"},
+		{"unsupported signet challenge", `471dac"`, `471dae"`,
+			"signetChallenge"},
+		{"short snapshot header", `ffff7f2002000000"`, `ffff7f20"`,
+			"header snapshot 0"},
+		{"bad snapshot work", `"work": "2"`, `"work": "-2"`,
+			"header snapshot 0"},
+	}
+	for _, test := range tests {
+		desc := strings.Replace(simNetDesc, test.old, test.new, 1)
+		_, err := loadNetworkDesc(strings.NewReader(desc))
+		if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+			t.Errorf("%s: got error %v, want error containing %q",
+				test.name, err, test.wantErr)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+rddnetgen generates Go source which defines the parameters of a custom Reddcoin
+network from a JSON description of it.  This allows private networks to be
+compiled into applications instead of being loaded at runtime.
+
+The generated source is written in the style of the standard networks in
+rddnet.  It defines the genesis coinbase transaction, merkle root, hash, and
+block as variables along with a rddnet.Params literal that refers to them.  A
+test which checks the serialized bytes and hash of the genesis block, like the
+ones for the standard networks, is generated alongside it.
+
+Usage:
+
+	rddnetgen [flags] -in devnet.json -out devnet
+
+The flags are:
+
+	-in file
+		The JSON network description to read.
+	-out path
+		The path of the generated files without the .go extension.
+		path.go and path_test.go are written.
+	-package name
+		The package to generate the source for (default main).  When the
+		package is rddnet, the source uses the package internals directly.
+	-nohelpers
+		Do not emit the newShaHashFromStr helper.  Use this for every
+		network after the first one generated into the same package.
+
+The description mirrors the fields of rddnet.Params.  Magics and difficulty
+bits may be given as numbers or as strings holding hexadecimal numbers.  When
+the genesis nonce is omitted, the first nonce which satisfies the genesis
+difficulty bits is used, which is only practical for low difficulty networks:
+
+	{
+		"varName": "devNet",
+		"name": "devnet",
+		"net": "0xd9b4bef9",
+		"defaultPort": "28555",
+		"rpcPort": 28556,
+		"aliases": ["dev"],
+		"genesis": {
+			"version": 1,
+			"timestamp": 1401292357,
+			"bits": "0x207fffff",
+			"txVersion": 1,
+			"signatureScript": "04ffff001d010445...",
+			"outputs": [{"value": 5000000000, "pkScript": "4104...ac"}]
+		},
+		"powLimit": "7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+		"powLimitBits": "0x207fffff",
+		"resetMinDifficulty": true,
+		"posvTxVersion": 2,
+		"coinbaseMaturity": 50,
+		"coinstakeMaturity": 50,
+		"blockLimits": [{"height": 0, "maxSize": 1000000, "maxSigOps": 20000}],
+		"checkpoints": [],
+		"headerSnapshots": [{"height": 0, "header": "0100000000...ffff7f2002000000", "work": "2"}],
+		"checkpointPubKeys": ["022d8ec3e113b78c2e1fb1696664849fa7330193aff35bff41165f73a1f035471d"],
+		"signetChallenge": "21022d8ec3e113b78c2e1fb1696664849fa7330193aff35bff41165f73a1f035471dac",
+		"policy": {"relayNonStdTxs": true, "minRelayTxFee": 1000, "dustThreshold": 546},
+		"pubKeyHashAddrID": "0x3f",
+		"scriptHashAddrID": "0x7b",
+		"privateKeyID": "0x64",
+		"hdPrivateKeyID": "0420b900",
+		"hdPublicKeyID": "0420bd3a",
+		"hdCoinType": 115
+	}
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	in := flag.String("in", "", "JSON network description to read")
+	out := flag.String("out", "", "path of the generated files without "+
+		"the .go extension")
+	pkg := flag.String("package", "main", "package to generate the "+
+		"source for")
+	noHelpers := flag.Bool("nohelpers", false, "do not emit the "+
+		"newShaHashFromStr helper")
+	flag.Parse()
+
+	if *in == "" || *out == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(*in, *out, genConfig{
+		Package: *pkg,
+		Source:  filepath.Base(*in),
+		Helpers: !*noHelpers,
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "rddnetgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run generates the source for the description in the file named in and
+// writes it to out.go and out_test.go.
+func run(in, out string, cfg genConfig) error {
+	f, err := os.Open(in)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	desc, err := loadNetworkDesc(f)
+	if err != nil {
+		return fmt.Errorf("%s: %v", in, err)
+	}
+	src, testSrc, err := generate(desc, cfg)
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(out+".go", src, 0644); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(out+"_test.go", testSrc, 0644)
+}
@@ -0,0 +1,247 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Collision describes an identifier which is shared by several networks.
+// Networks which share a value of Field can not be told apart by it, so for
+// example nodes of networks which share a default port can not run on the same
+// host without configuration, and addresses of networks which share an
+// address magic are valid on each of them.
+type Collision struct {
+	// Field is the path of the shared field in Params, such as
+	// DefaultPort.  Values used for different fields by different networks
+	// are reported with the fields joined by a slash.  For example, a port
+	// used as the default port by one network and as the RPC port by
+	// another is reported with the Field DefaultPort/RPCPort since nodes of
+	// both networks can not run on the same host.  Likewise, a magic used
+	// as the pay-to-pubkey-hash magic by one network and as the
+	// pay-to-script-hash magic by another is reported with the Field
+	// PubKeyHashAddrID/ScriptHashAddrID since addresses with such a magic
+	// can not be decoded without knowing the network.
+	Field string
+
+	// Value is the shared value formatted like DiffParams formats it.
+	Value string
+
+	// Networks are the names of the networks which share the value, in
+	// the order they were passed or registered in.
+	Networks []string
+}
+
+// String returns the collision in a human-readable form.
+func (c Collision) String() string {
+	return fmt.Sprintf("%s %s shared by %s", c.Field, c.Value,
+		strings.Join(c.Networks, ", "))
+}
+
+// collisionField is an identifying field of a network along with a function
+// which returns its formatted value.  Fields which share a namespace with
+// other fields, like the ports of the different servers of a node, list the
+// names of all of them and return a value for each.
+type collisionField struct {
+	names  []string
+	values func(p *Params) []string
+}
+
+// singleField returns a collisionField for a field which does not share its
+// namespace with other fields.
+func singleField(name string, value func(p *Params) string) collisionField {
+	return collisionField{[]string{name}, func(p *Params) []string {
+		return []string{value(p)}
+	}}
+}
+
+// collisionFields are the identifying fields of a network which must not be
+// shared with other networks, in the order collisions are reported in.
+var collisionFields = []collisionField{
+	singleField("Net", func(p *Params) string {
+		return fmt.Sprintf("%#08x", uint32(p.Net))
+	}),
+	{[]string{"DefaultPort", "RPCPort", "WSPort"}, func(p *Params) []string {
+		return []string{formatP2PPort(p), formatPort(p.RPCPort),
+			formatPort(p.WSPort)}
+	}},
+	singleField("GenesisHash", func(p *Params) string {
+		return formatHash(p.GenesisHash)
+	}),
+	singleField("PubKeyHashAddrID", func(p *Params) string {
+		return fmt.Sprintf("%#02x", p.PubKeyHashAddrID)
+	}),
+	singleField("ScriptHashAddrID", func(p *Params) string {
+		return fmt.Sprintf("%#02x", p.ScriptHashAddrID)
+	}),
+	singleField("PrivateKeyID", func(p *Params) string {
+		return fmt.Sprintf("%#02x", p.PrivateKeyID)
+	}),
+	singleField("HDPrivateKeyID", func(p *Params) string {
+		return fmt.Sprintf("%x", p.HDPrivateKeyID[:])
+	}),
+	singleField("HDPublicKeyID", func(p *Params) string {
+		return fmt.Sprintf("%x", p.HDPublicKeyID[:])
+	}),
+}
+
+// ambiguousMagic is a pair of base58 magics which must not be shared by
+// different networks along with functions which return them.
+type ambiguousMagic struct {
+	name string
+	a, b func(p *Params) byte
+}
+
+// ambiguousMagics are the pairs of base58 magics of different encodings which
+// must not be shared by different networks, in the order collisions are
+// reported in.  NewDevNet picks every magic of a development network so none
+// of these are reported for it.
+var ambiguousMagics = []ambiguousMagic{
+	{"PubKeyHashAddrID/ScriptHashAddrID",
+		func(p *Params) byte { return p.PubKeyHashAddrID },
+		func(p *Params) byte { return p.ScriptHashAddrID }},
+	{"PubKeyHashAddrID/PrivateKeyID",
+		func(p *Params) byte { return p.PubKeyHashAddrID },
+		func(p *Params) byte { return p.PrivateKeyID }},
+	{"ScriptHashAddrID/PrivateKeyID",
+		func(p *Params) byte { return p.ScriptHashAddrID },
+		func(p *Params) byte { return p.PrivateKeyID }},
+}
+
+// formatPort returns the port in decimal or the empty string for zero.
+func formatPort(port uint16) string {
+	if port == 0 {
+		return ""
+	}
+	return strconv.Itoa(int(port))
+}
+
+// formatP2PPort returns the peer-to-peer port of the network in decimal.  It
+// is DefaultPort, or P2PPort for networks which only set the typed port.
+func formatP2PPort(p *Params) string {
+	if p.DefaultPort == "" {
+		return formatPort(p.P2PPort)
+	}
+	if port, err := strconv.ParseUint(p.DefaultPort, 10, 16); err == nil {
+		return formatPort(uint16(port))
+	}
+	return p.DefaultPort
+}
+
+// FindCollisions returns every identifying value shared by two or more of the
+// passed networks: the network magic, default ports, genesis block hash,
+// address and private key magics, and extended key magics.  The default
+// peer-to-peer, RPC, and websocket ports are compared with each other as well
+// since no two servers on a host can share a port.  Empty values are not
+// reported.  Collisions are ordered by field in the order listed above and
+// then by the first network which uses the value.  They are followed by the
+// magics one network uses for another encoding than other networks: address
+// magics used for both pay-to-pubkey-hash and pay-to-script-hash addresses,
+// and address magics used as a private key magic.  Nil is returned when no
+// value is shared.
+func FindCollisions(nets ...*Params) []Collision {
+	var collisions []Collision
+	for _, field := range collisionFields {
+		// Collect the networks using each value along with the names of
+		// the fields they use it for in the order the values are first
+		// used in.
+		type use struct {
+			networks []*Params
+			fields   map[int]struct{}
+		}
+		var values []string
+		uses := make(map[string]*use)
+		for i := range field.names {
+			for _, params := range nets {
+				value := field.values(params)[i]
+				if value == "" {
+					continue
+				}
+				u, ok := uses[value]
+				if !ok {
+					values = append(values, value)
+					u = &use{fields: make(map[int]struct{})}
+					uses[value] = u
+				}
+				u.fields[i] = struct{}{}
+				if !containsParams(u.networks, params) {
+					u.networks = append(u.networks, params)
+				}
+			}
+		}
+		for _, value := range values {
+			u := uses[value]
+			if len(u.networks) < 2 {
+				continue
+			}
+			var names, networks []string
+			for i, name := range field.names {
+				if _, ok := u.fields[i]; ok {
+					names = append(names, name)
+				}
+			}
+			for _, params := range nets {
+				if containsParams(u.networks, params) {
+					networks = append(networks, params.Name)
+				}
+			}
+			collisions = append(collisions, Collision{
+				Field:    strings.Join(names, "/"),
+				Value:    value,
+				Networks: networks,
+			})
+		}
+	}
+
+	// Magics used for different encodings by different networks make
+	// the encoded strings ambiguous.
+	for _, magic := range ambiguousMagics {
+		reported := make(map[byte]struct{})
+		for _, params := range nets {
+			id := magic.a(params)
+			if _, ok := reported[id]; ok {
+				continue
+			}
+			var networks []string
+			var ambiguous bool
+			for _, other := range nets {
+				switch {
+				case magic.a(other) == id:
+					networks = append(networks, other.Name)
+				case magic.b(other) == id:
+					networks = append(networks, other.Name)
+					ambiguous = true
+				}
+			}
+			if ambiguous {
+				reported[id] = struct{}{}
+				collisions = append(collisions, Collision{
+					Field:    magic.name,
+					Value:    fmt.Sprintf("%#02x", id),
+					Networks: networks,
+				})
+			}
+		}
+	}
+	return collisions
+}
+
+// containsParams returns whether the passed parameters are in the list.
+func containsParams(list []*Params, params *Params) bool {
+	for _, other := range list {
+		if other == params {
+			return true
+		}
+	}
+	return false
+}
+
+// Collisions returns every identifying value shared by two or more of the
+// default and registered networks as described by FindCollisions.
+func Collisions() []Collision {
+	return FindCollisions(registeredParams...)
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet_test
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/reddcoin-project/rddnet"
+)
+
+// TestDefaultNetCollisions pins the identifiers shared by the default
+// networks.  The regression test network deliberately shares its address and
+// extended key magics with the public test network, like on Bitcoin.  Any
+// other shared identifier must be added here on purpose.
+//
+// The default ports of the test networks are also the ones of Bitcoin's test
+// networks, which can not be detected here since Bitcoin's networks are not
+// registered.
+func TestDefaultNetCollisions(t *testing.T) {
+	test := []string{"regtest", "testnet3"}
+	want := []rddnet.Collision{
+		{Field: "PubKeyHashAddrID", Value: "0x6f", Networks: test},
+		{Field: "ScriptHashAddrID", Value: "0xc4", Networks: test},
+		{Field: "PrivateKeyID", Value: "0xef", Networks: test},
+		{Field: "HDPrivateKeyID", Value: "04358394", Networks: test},
+		{Field: "HDPublicKeyID", Value: "043587cf", Networks: test},
+	}
+	got := rddnet.FindCollisions(defaultNets...)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindCollisions: got %v want %v", got, want)
+	}
+
+	// Networks registered by other tests share no identifiers with the
+	// default networks, so only their collisions are reported here.  The
+	// default networks are registered with testnet3 before regtest.
+	for i := range want {
+		want[i].Networks = []string{"testnet3", "regtest"}
+	}
+	if got := rddnet.Collisions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Collisions: got %v want %v", got, want)
+	}
+}
+
+// TestCollisions ensures Collisions reports identifiers shared with registered
+// networks.
+func TestCollisions(t *testing.T) {
+	params := &rddnet.Params{
+		Name:             "collisionnet",
+		Net:              0xffffffb0,
+		PubKeyHashAddrID: rddnet.MainNetParams.PubKeyHashAddrID,
+		ScriptHashAddrID: 0xb0,
+		PrivateKeyID:     0xb1,
+		HDPrivateKeyID:   [4]byte{0xb0, 0xb0, 0xb0, 0xb0},
+		HDPublicKeyID:    [4]byte{0xb1, 0xb1, 0xb1, 0xb1},
+	}
+	params.Policy = rddnet.MainNetParams.Policy
+	params.RelayNonStdTxs = params.Policy.RelayNonStdTxs
+	if err := rddnet.TstRegister(t, params); err != nil {
+		t.Fatalf("Register: unexpected error %v", err)
+	}
+
+	want := rddnet.Collision{
+		Field: "PubKeyHashAddrID",
+		Value: fmt.Sprintf("%#02x", rddnet.MainNetParams.PubKeyHashAddrID),
+		Networks: []string{rddnet.MainNetParams.Name,
+			"collisionnet"},
+	}
+	got := rddnet.Collisions()
+	if len(got) == 0 || !reflect.DeepEqual(got[0], want) {
+		t.Errorf("Collisions: got %v want %v first", got, want)
+	}
+}
+
+// TestFindCollisions ensures every kind of shared identifier is reported.
+func TestFindCollisions(t *testing.T) {
+	a := rddnet.SimNetParams.Clone()
+	a.Name = "a"
+	a.WSPort = 18557
+	b := rddnet.SimNetParams.Clone()
+	b.Name = "b"
+	b.PubKeyHashAddrID = 0x01
+	b.ScriptHashAddrID = a.PubKeyHashAddrID
+	b.PrivateKeyID = 0x02
+	b.HDPrivateKeyID = [4]byte{1, 2, 3, 4}
+	c := rddnet.TestNet3Params.Clone()
+	c.Name = "c"
+	c.DefaultPort = a.DefaultPort
+	c.WSPort = a.WSPort
+	c.GenesisHash = nil
+
+	want := []rddnet.Collision{
+		{Field: "Net", Value: "0x12141c16", Networks: []string{"a", "b"}},
+		{Field: "DefaultPort", Value: "18555",
+			Networks: []string{"a", "b", "c"}},
+		{Field: "RPCPort", Value: "18556", Networks: []string{"a", "b"}},
+		{Field: "WSPort", Value: "18557", Networks: []string{"a", "c"}},
+		{Field: "GenesisHash", Value: a.GenesisHash.String(),
+			Networks: []string{"a", "b"}},
+		{Field: "HDPublicKeyID", Value: "0420bd3a",
+			Networks: []string{"a", "b"}},
+		{Field: "PubKeyHashAddrID/ScriptHashAddrID", Value: "0x3f",
+			Networks: []string{"a", "b"}},
+	}
+	got := rddnet.FindCollisions(a, b, c)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindCollisions: got %v want %v", got, want)
+	}
+	if got := want[1].String(); got != "DefaultPort 18555 shared by a, b, c" {
+		t.Errorf("String: got %q", got)
+	}
+	if got := rddnet.FindCollisions(a); got != nil {
+		t.Errorf("FindCollisions of a single network: got %v", got)
+	}
+
+	// Ports are compared across the servers of different networks,
+	// including networks which only set the typed peer-to-peer port.
+	pa := rddnet.MainNetParams.Clone()
+	pa.Name = "pa"
+	pb := rddnet.TestNet3Params.Clone()
+	pb.Name = "pb"
+	pb.RPCPort = pa.P2PPort
+	pc := rddnet.SimNetParams.Clone()
+	pc.Name = "pc"
+	pc.DefaultPort = ""
+	pc.WSPort = pa.RPCPort
+	pd := &rddnet.Params{
+		Name:             "pd",
+		Net:              0xffffffb2,
+		P2PPort:          pc.RPCPort,
+		PubKeyHashAddrID: 0x01,
+		ScriptHashAddrID: 0x02,
+		PrivateKeyID:     0x03,
+		HDPrivateKeyID:   [4]byte{0x01},
+		HDPublicKeyID:    [4]byte{0x02},
+	}
+
+	want = []rddnet.Collision{
+		{Field: "DefaultPort/RPCPort", Value: pa.DefaultPort,
+			Networks: []string{"pa", "pb"}},
+		{Field: "DefaultPort/RPCPort",
+			Value:    strconv.Itoa(int(pc.RPCPort)),
+			Networks: []string{"pc", "pd"}},
+		{Field: "RPCPort/WSPort",
+			Value:    strconv.Itoa(int(pa.RPCPort)),
+			Networks: []string{"pa", "pc"}},
+	}
+	got = rddnet.FindCollisions(pa, pb, pc, pd)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindCollisions: got %v want %v", got, want)
+	}
+}
+
+// TestFindCollisionsPrivateKeyID ensures private key magics which are the
+// address magic of another network are reported.
+func TestFindCollisionsPrivateKeyID(t *testing.T) {
+	a := rddnet.SimNetParams.Clone()
+	a.Name = "a"
+	b := rddnet.TestNet3Params.Clone()
+	b.Name = "b"
+	b.PrivateKeyID = a.PubKeyHashAddrID
+	c := rddnet.MainNetParams.Clone()
+	c.Name = "c"
+	c.PrivateKeyID = a.ScriptHashAddrID
+
+	want := []rddnet.Collision{
+		{Field: "PubKeyHashAddrID/PrivateKeyID", Value: "0x3f",
+			Networks: []string{"a", "b"}},
+		{Field: "ScriptHashAddrID/PrivateKeyID", Value: "0x7b",
+			Networks: []string{"a", "c"}},
+	}
+	got := rddnet.FindCollisions(a, b, c)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindCollisions: got %v want %v", got, want)
+	}
+
+	// A network may use the same magic for its own keys and addresses.
+	a.PrivateKeyID = a.PubKeyHashAddrID
+	if got := rddnet.FindCollisions(a, rddnet.MainNetParams.Clone()); got != nil {
+		t.Errorf("FindCollisions: got %v want none", got)
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet
+
+import (
+	"errors"
+)
+
+const (
+	// privKeyBytesLen is the length of a serialized secp256k1 private key.
+	privKeyBytesLen = 32
+
+	// compressMagic is the byte appended to a WIF private key to mark that
+	// the public key derived from it is to be serialized in compressed
+	// form.
+	compressMagic byte = 0x01
+
+	// serializedKeyLen is the length of a serialized BIP0032 extended key
+	// without the trailing checksum:
+	//   version (4) || depth (1) || parent fingerprint (4) ||
+	//   child number (4) || chain code (32) || key data (33)
+	serializedKeyLen = 4 + 1 + 4 + 4 + 32 + 33
+)
+
+var (
+	// ErrInvalidWIF describes an error where a base58check decoded WIF
+	// private key does not hold a version byte followed by a 32 byte
+	// private key and an optional compressed public key flag.
+	ErrInvalidWIF = errors.New("malformed WIF private key")
+
+	// ErrUnknownPrivateKeyID describes an error where the version byte of
+	// a WIF private key is not used by any default or registered network.
+	ErrUnknownPrivateKeyID = errors.New("unknown WIF private key version " +
+		"byte")
+
+	// ErrInvalidExtendedKey describes an error where a base58check decoded
+	// extended key is not the length of a serialized BIP0032 extended key.
+	ErrInvalidExtendedKey = errors.New("malformed extended key")
+)
+
+// ConvertAddress re-encodes the passed pay-to-pubkey-hash or
+// pay-to-script-hash address for the passed network.  The returned address
+// pays to the same hash using the address magic of the network for the same
+// kind of address.  Any error that DetectAddressNetwork would return for the
+// address is returned.
+func ConvertAddress(addr string, to *Params) (string, error) {
+	kind, _, err := DetectAddressNetwork(addr)
+	if err != nil {
+		return "", err
+	}
+	_, hash, err := decodeAddress(addr)
+	if err != nil {
+		return "", err
+	}
+
+	id := to.PubKeyHashAddrID
+	if kind == ScriptHashAddress {
+		id = to.ScriptHashAddrID
+	}
+	return checkEncode(hash, []byte{id}), nil
+}
+
+// ConvertWIF re-encodes the passed Wallet Import Format private key for the
+// passed network.  The private key and compressed public key flag are kept
+// while the version byte is replaced with the PrivateKeyID of the network.
+// ErrUnknownPrivateKeyID is returned when no default or registered network
+// uses the version byte of the key.
+func ConvertWIF(wif string, to *Params) (string, error) {
+	decoded, err := checkDecode(wif)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case len(decoded) == 1+privKeyBytesLen:
+	case len(decoded) == 1+privKeyBytesLen+1 &&
+		decoded[1+privKeyBytesLen] == compressMagic:
+	default:
+		return "", ErrInvalidWIF
+	}
+
+	known := false
+	for _, params := range registeredParams {
+		if params.PrivateKeyID == decoded[0] {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return "", ErrUnknownPrivateKeyID
+	}
+
+	return checkEncode(decoded[1:], []byte{to.PrivateKeyID}), nil
+}
+
+// ConvertExtendedKey re-encodes the passed BIP0032 extended private or public
+// key for the passed network.  The depth, parent fingerprint, child number,
+// chain code, and key data are kept while the version bytes are replaced with
+// the HDPrivateKeyID or HDPublicKeyID of the network depending on the kind of
+// key.  ErrUnknownHDKeyID is returned when no default or registered network
+// uses the version bytes of the key.
+func ConvertExtendedKey(key string, to *Params) (string, error) {
+	decoded, err := checkDecode(key)
+	if err != nil {
+		return "", err
+	}
+	if len(decoded) != serializedKeyLen {
+		return "", ErrInvalidExtendedKey
+	}
+
+	var version [4]byte
+	copy(version[:], decoded[:4])
+	var newVersion []byte
+	for _, params := range registeredParams {
+		if version == params.HDPrivateKeyID {
+			newVersion = to.HDPrivateKeyID[:]
+			break
+		}
+		if version == params.HDPublicKeyID {
+			newVersion = to.HDPublicKeyID[:]
+			break
+		}
+	}
+	if newVersion == nil {
+		return "", ErrUnknownHDKeyID
+	}
+
+	return checkEncode(decoded[4:], newVersion), nil
+}
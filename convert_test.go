@@ -0,0 +1,218 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet
+
+import (
+	"bytes"
+	"testing"
+	"testing/quick"
+)
+
+// convertNets are the networks addresses and keys are converted between in the
+// tests below.
+var convertNets = []*Params{
+	&MainNetParams,
+	&TestNet3Params,
+	&RegressionNetParams,
+	&SimNetParams,
+}
+
+// TestConvertAddress ensures a known mainnet address converts to the expected
+// testnet3 address and back.
+func TestConvertAddress(t *testing.T) {
+	const mainAddr = "RuLeTmnXHQsiNjuDp512QYgoJ5jmPTs5UX"
+	const testAddr = "n2EohCgvnS3XGQsZ33exepJ8mJcvujsjzm"
+
+	got, err := ConvertAddress(mainAddr, &TestNet3Params)
+	if err != nil || got != testAddr {
+		t.Errorf("ConvertAddress to testnet3: got %s, %v want %s", got,
+			err, testAddr)
+	}
+	got, err = ConvertAddress(testAddr, &MainNetParams)
+	if err != nil || got != mainAddr {
+		t.Errorf("ConvertAddress to mainnet: got %s, %v want %s", got,
+			err, mainAddr)
+	}
+
+	_, err = ConvertAddress("1MirQ9bwyQcGVJPwKUgapu5ouK2E2Ey4gX",
+		&MainNetParams)
+	if err != ErrUnknownAddressID {
+		t.Errorf("ConvertAddress of unknown address: got %v want %v",
+			err, ErrUnknownAddressID)
+	}
+}
+
+// TestConvertAddressRoundTrip ensures the hash of random addresses survives
+// conversion between every pair of networks and that converting back results
+// in the original address.
+func TestConvertAddressRoundTrip(t *testing.T) {
+	f := func(hash [ripemd160Size]byte, scriptHash bool) bool {
+		for _, from := range convertNets {
+			id := from.PubKeyHashAddrID
+			if scriptHash {
+				id = from.ScriptHashAddrID
+			}
+			addr := checkEncode(hash[:], []byte{id})
+
+			for _, to := range convertNets {
+				converted, err := ConvertAddress(addr, to)
+				if err != nil {
+					t.Logf("ConvertAddress(%s, %s): %v", addr,
+						to.Name, err)
+					return false
+				}
+				wantID := to.PubKeyHashAddrID
+				if scriptHash {
+					wantID = to.ScriptHashAddrID
+				}
+				gotID, gotHash, err := decodeAddress(converted)
+				if err != nil || gotID != wantID ||
+					!bytes.Equal(gotHash, hash[:]) {
+					return false
+				}
+				back, err := ConvertAddress(converted, from)
+				if err != nil || back != addr {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestConvertWIFRoundTrip ensures the private key and compression flag of
+// random WIF private keys survive conversion between every pair of networks.
+func TestConvertWIFRoundTrip(t *testing.T) {
+	f := func(key [privKeyBytesLen]byte, compressed bool) bool {
+		payload := key[:]
+		if compressed {
+			payload = append(payload, compressMagic)
+		}
+
+		for _, from := range convertNets {
+			wif := checkEncode(payload, []byte{from.PrivateKeyID})
+			for _, to := range convertNets {
+				converted, err := ConvertWIF(wif, to)
+				if err != nil {
+					t.Logf("ConvertWIF(%s, %s): %v", wif,
+						to.Name, err)
+					return false
+				}
+				decoded, err := checkDecode(converted)
+				if err != nil || decoded[0] != to.PrivateKeyID ||
+					!bytes.Equal(decoded[1:], payload) {
+					return false
+				}
+				back, err := ConvertWIF(converted, from)
+				if err != nil || back != wif {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestConvertExtendedKeyRoundTrip ensures the serialized contents of random
+// extended private and public keys survive conversion between every pair of
+// networks.
+func TestConvertExtendedKeyRoundTrip(t *testing.T) {
+	f := func(payload [serializedKeyLen - 4]byte, private bool) bool {
+		for _, from := range convertNets {
+			version, wantFn := from.HDPublicKeyID, hdPublicKeyID
+			if private {
+				version, wantFn = from.HDPrivateKeyID, hdPrivateKeyID
+			}
+			key := checkEncode(payload[:], version[:])
+			for _, to := range convertNets {
+				converted, err := ConvertExtendedKey(key, to)
+				if err != nil {
+					t.Logf("ConvertExtendedKey(%s, %s): %v",
+						key, to.Name, err)
+					return false
+				}
+				want := wantFn(to)
+				decoded, err := checkDecode(converted)
+				if err != nil || !bytes.Equal(decoded[:4], want[:]) ||
+					!bytes.Equal(decoded[4:], payload[:]) {
+					return false
+				}
+				back, err := ConvertExtendedKey(converted, from)
+				if err != nil || back != key {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+// hdPrivateKeyID and hdPublicKeyID return the extended key magics of the
+// passed network.
+func hdPrivateKeyID(p *Params) [4]byte { return p.HDPrivateKeyID }
+func hdPublicKeyID(p *Params) [4]byte  { return p.HDPublicKeyID }
+
+// TestConvertErrors ensures malformed keys are rejected.
+func TestConvertErrors(t *testing.T) {
+	var key [privKeyBytesLen]byte
+	tests := []struct {
+		name    string
+		convert func(string, *Params) (string, error)
+		in      string
+		err     error
+	}{
+		{
+			name:    "wif bad compression flag",
+			convert: ConvertWIF,
+			in: checkEncode(append(key[:], 0x02),
+				[]byte{MainNetParams.PrivateKeyID}),
+			err: ErrInvalidWIF,
+		},
+		{
+			name:    "wif short key",
+			convert: ConvertWIF,
+			in: checkEncode(key[1:],
+				[]byte{MainNetParams.PrivateKeyID}),
+			err: ErrInvalidWIF,
+		},
+		{
+			name:    "wif unknown version",
+			convert: ConvertWIF,
+			in:      checkEncode(key[:], []byte{0x80}),
+			err:     ErrUnknownPrivateKeyID,
+		},
+		{
+			name:    "extended key short",
+			convert: ConvertExtendedKey,
+			in: checkEncode(make([]byte, serializedKeyLen-5),
+				MainNetParams.HDPrivateKeyID[:]),
+			err: ErrInvalidExtendedKey,
+		},
+		{
+			name:    "extended key unknown version",
+			convert: ConvertExtendedKey,
+			in: checkEncode(make([]byte, serializedKeyLen-4),
+				[]byte{0xff, 0xff, 0xff, 0xff}),
+			err: ErrUnknownHDKeyID,
+		},
+	}
+
+	for _, test := range tests {
+		_, err := test.convert(test.in, &MainNetParams)
+		if err != test.err {
+			t.Errorf("%s: unexpected error: got %v, want %v", test.name,
+				err, test.err)
+		}
+	}
+}
@@ -0,0 +1,323 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/reddcoin-project/rddnet/merkle"
+	"github.com/reddcoin-project/rddwire"
+)
+
+// devNetPowLimitBits is the difficulty of the genesis blocks of development
+// networks, which is met by about every other nonce.
+const devNetPowLimitBits = 0x207fffff
+
+// These constants define the range development network ports are derived in.
+const (
+	devNetMinPort   = 20000
+	devNetPortRange = 40000
+)
+
+// ErrInvalidDevNetName describes an error where a development network could
+// not be created due to its name being empty.
+var ErrInvalidDevNetName = errors.New("invalid development network name")
+
+// ErrDevNetNoNonce describes an error where no nonce gives the genesis block of
+// a development network a hash which meets its difficulty.
+var ErrDevNetNoNonce = errors.New("no nonce solves the development network " +
+	"genesis block")
+
+// devNetConfig holds the settings applied by DevNetOptions.
+type devNetConfig struct {
+	timestamp time.Time
+	register  func(*Params) error
+}
+
+// DevNetOption configures the development networks created by NewDevNet.
+type DevNetOption func(*devNetConfig)
+
+// DevNetTimestamp sets the timestamp of the genesis block of the development
+// network.  It defaults to the timestamp of the simnet genesis block.
+func DevNetTimestamp(timestamp time.Time) DevNetOption {
+	return func(cfg *devNetConfig) {
+		cfg.timestamp = timestamp
+	}
+}
+
+// DevNetRegister sets the function the development network is registered
+// with.  It defaults to Register, which registers the network into the
+// registry of this package.  A nil function creates the network without
+// registering it.
+func DevNetRegister(register func(*Params) error) DevNetOption {
+	return func(cfg *devNetConfig) {
+		cfg.register = register
+	}
+}
+
+// devNetSeed is a deterministic stream of bytes derived from the name of a
+// development network.  Every value derived for the network is read from it,
+// so a value which collides with another network is replaced by the next one.
+type devNetSeed struct {
+	block [sha256.Size]byte
+	used  int
+}
+
+// devNetNameHash returns the hash of the name of a development network which
+// both seeds its values and is committed to by its genesis block.
+func devNetNameHash(name string) [sha256.Size]byte {
+	return sha256.Sum256([]byte("rddnet devnet " + name))
+}
+
+// newDevNetSeed returns the seed of the development network with the passed
+// name.
+func newDevNetSeed(name string) *devNetSeed {
+	return &devNetSeed{block: devNetNameHash(name)}
+}
+
+// read returns the next n bytes of the seed.
+func (s *devNetSeed) read(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		if s.used == len(s.block) {
+			s.block = sha256.Sum256(s.block[:])
+			s.used = 0
+		}
+		b[i] = s.block[s.used]
+		s.used++
+	}
+	return b
+}
+
+// devNetUsed holds the identifiers already used by the default and registered
+// networks, which development networks must not reuse.
+type devNetUsed struct {
+	nets    map[rddwire.ReddcoinNet]struct{}
+	ports   map[uint16]struct{}
+	addrIDs map[byte]struct{}
+	hdIDs   map[[4]byte]struct{}
+	hrps    map[string]struct{}
+}
+
+// usedDevNetIDs returns the identifiers of the default and registered networks.
+func usedDevNetIDs() *devNetUsed {
+	used := &devNetUsed{
+		nets:    make(map[rddwire.ReddcoinNet]struct{}),
+		ports:   make(map[uint16]struct{}),
+		addrIDs: make(map[byte]struct{}),
+		hdIDs:   make(map[[4]byte]struct{}),
+		hrps:    make(map[string]struct{}),
+	}
+	for _, params := range registeredParams {
+		used.nets[params.Net] = struct{}{}
+		for _, port := range []uint16{params.P2PPort, params.RPCPort,
+			params.WSPort} {
+
+			if port != 0 {
+				used.ports[port] = struct{}{}
+			}
+		}
+		used.addrIDs[params.PubKeyHashAddrID] = struct{}{}
+		used.addrIDs[params.ScriptHashAddrID] = struct{}{}
+		used.addrIDs[params.PrivateKeyID] = struct{}{}
+		used.hdIDs[params.HDPrivateKeyID] = struct{}{}
+		used.hdIDs[params.HDPublicKeyID] = struct{}{}
+	}
+	for hrp := range bech32SegwitPrefixes {
+		used.hrps[hrp] = struct{}{}
+	}
+	return used
+}
+
+// addrID returns the next address magic of the seed which is not used by any
+// other network.  Once every magic is used, magics are shared with other
+// networks like the default test networks share theirs.
+func (s *devNetSeed) addrID(used map[byte]struct{}) byte {
+	for i := 0; i < 256; i++ {
+		id := s.read(1)[0]
+		if _, ok := used[id]; !ok {
+			used[id] = struct{}{}
+			return id
+		}
+	}
+	return s.read(1)[0]
+}
+
+// port returns the next port of the seed which is not used by any other
+// network.
+func (s *devNetSeed) port(used map[uint16]struct{}) uint16 {
+	for {
+		port := uint16(devNetMinPort + binary.LittleEndian.Uint32(
+			s.read(4))%devNetPortRange)
+		if _, ok := used[port]; !ok {
+			used[port] = struct{}{}
+			return port
+		}
+	}
+}
+
+// hdID returns the next extended key magic of the seed which is not used by
+// any other network.
+func (s *devNetSeed) hdID(used map[[4]byte]struct{}) [4]byte {
+	for {
+		var id [4]byte
+		copy(id[:], s.read(4))
+		if _, ok := used[id]; !ok {
+			used[id] = struct{}{}
+			return id
+		}
+	}
+}
+
+// solveDevNetHeader sets the nonce of the passed header to the first nonce up
+// to and including maxNonce which gives the header a hash not exceeding the
+// target.  ErrDevNetNoNonce is returned when no nonce does.
+func solveDevNetHeader(header *rddwire.BlockHeader, target *big.Int,
+	maxNonce uint32) error {
+
+	for nonce := uint32(0); ; nonce++ {
+		header.Nonce = nonce
+		hash, err := header.BlockSha()
+		if err != nil {
+			return err
+		}
+		// A ShaHash is in little-endian, but the big package wants
+		// the bytes in big-endian, so reverse them.
+		for i := 0; i < rddwire.HashSize/2; i++ {
+			hash[i], hash[rddwire.HashSize-1-i] =
+				hash[rddwire.HashSize-1-i], hash[i]
+		}
+		if new(big.Int).SetBytes(hash[:]).Cmp(target) <= 0 {
+			return nil
+		}
+		if nonce == maxNonce {
+			return ErrDevNetNoNonce
+		}
+	}
+}
+
+// devNetGenesis returns a genesis block for the development network with the
+// passed name.  Its coinbase commits to the hash of the name, which fits in a
+// single push however long the name is, so every development network has a
+// different genesis block.  Its nonce is the first one which meets the
+// difficulty of devNetPowLimitBits.  ErrDevNetNoNonce is returned when no
+// nonce does.
+func devNetGenesis(name string, timestamp time.Time) (*rddwire.MsgBlock, error) {
+	tx := SimNetParams.GenesisBlock.Transactions[0].Copy()
+	nameHash := devNetNameHash(name)
+	script := append([]byte("rddnet devnet "), nameHash[:]...)
+	tx.TxIn[0].SignatureScript = append([]byte{byte(len(script))},
+		script...)
+
+	block := &rddwire.MsgBlock{
+		Header: rddwire.BlockHeader{
+			Version: 1,
+			MerkleRoot: merkle.Root([]rddwire.ShaHash{
+				SimNetParams.TxSha(tx)}),
+			Timestamp: time.Unix(timestamp.Unix(), 0),
+			Bits:      devNetPowLimitBits,
+		},
+		Transactions: []*rddwire.MsgTx{tx},
+	}
+
+	err := solveDevNetHeader(&block.Header,
+		CompactToBig(devNetPowLimitBits), math.MaxUint32)
+	if err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// NewDevNet returns the parameters of a new development network with the
+// passed name and registers them.  Development networks are private networks
+// for integration tests which are like the simulation test network, but each
+// one has its own genesis block, network magic, default ports, address and
+// extended key magics, and Bech32 human-readable part so they neither collide
+// with the default networks nor with each other.
+//
+// Every value is derived deterministically from the name, so creating a
+// network with the same name in another process produces the same
+// parameters, provided the same networks were registered before it.  Values
+// which are already used by a default or registered network are skipped.
+//
+// The genesis block is mined at the difficulty 0x207fffff, which is also the
+// proof of work limit of the network, so blocks are trivially mineable.
+// ErrInvalidDevNetName is returned for an empty name, ErrDevNetNoNonce is
+// returned when no nonce solves the genesis block, and any error returned
+// by the registration function set with DevNetRegister is returned as is, in
+// which case the network is not returned.
+func NewDevNet(name string, opts ...DevNetOption) (*Params, error) {
+	if name == "" {
+		return nil, ErrInvalidDevNetName
+	}
+	cfg := devNetConfig{
+		timestamp: SimNetParams.GenesisBlock.Header.Timestamp,
+		register:  Register,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	seed := newDevNetSeed(name)
+	used := usedDevNetIDs()
+
+	params := SimNetParams.Clone()
+	params.Name = name
+	params.Aliases = nil
+	params.Checkpoints = nil
+	params.HeaderSnapshots = nil
+	params.CheckpointPubKeys = nil
+	params.PowLimit = new(big.Int).Sub(new(big.Int).Lsh(bigOne, 255), bigOne)
+	params.PowLimitBits = devNetPowLimitBits
+	params.HDCoinType = 1 // BIP44 coin type of all test networks
+
+	for {
+		net := rddwire.ReddcoinNet(binary.LittleEndian.Uint32(seed.read(4)))
+		if _, ok := used.nets[net]; !ok && net != 0 {
+			params.Net = net
+			break
+		}
+	}
+	params.P2PPort = seed.port(used.ports)
+	params.DefaultPort = strconv.Itoa(int(params.P2PPort))
+	params.RPCPort = seed.port(used.ports)
+	params.WSPort = 0
+	params.PubKeyHashAddrID = seed.addrID(used.addrIDs)
+	params.ScriptHashAddrID = seed.addrID(used.addrIDs)
+	params.PrivateKeyID = seed.addrID(used.addrIDs)
+	params.HDPrivateKeyID = seed.hdID(used.hdIDs)
+	params.HDPublicKeyID = seed.hdID(used.hdIDs)
+	for {
+		params.Bech32HRPSegwit = "dev" + hex.EncodeToString(seed.read(3))
+		if _, ok := used.hrps[params.Bech32HRPSegwit]; !ok {
+			break
+		}
+	}
+
+	genesis, err := devNetGenesis(name, cfg.timestamp)
+	if err != nil {
+		return nil, err
+	}
+	params.GenesisBlock = genesis
+	hash, err := params.GenesisBlock.BlockSha()
+	if err != nil {
+		return nil, err
+	}
+	params.GenesisHash = &hash
+
+	if cfg.register != nil {
+		if err := cfg.register(params); err != nil {
+			return nil, err
+		}
+	}
+	return params, nil
+}
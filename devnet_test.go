@@ -0,0 +1,185 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/reddcoin-project/rddnet"
+)
+
+// TestNewDevNet ensures development networks are registered, have valid
+// genesis blocks, and collide neither with the default networks nor with each
+// other.
+func TestNewDevNet(t *testing.T) {
+	// Register the networks for the duration of the test only.
+	register := rddnet.DevNetRegister(func(p *rddnet.Params) error {
+		return rddnet.TstRegister(t, p)
+	})
+
+	alpha, err := rddnet.NewDevNet("devnet-alpha", register)
+	if err != nil {
+		t.Fatalf("NewDevNet: unexpected error %v", err)
+	}
+	beta, err := rddnet.NewDevNet("devnet-beta", register)
+	if err != nil {
+		t.Fatalf("NewDevNet: unexpected error %v", err)
+	}
+
+	for _, params := range []*rddnet.Params{alpha, beta} {
+		if got, err := rddnet.ParamsForName(params.Name); err != nil ||
+			got != params {
+
+			t.Errorf("%s: ParamsForName: got %v, %v", params.Name,
+				got, err)
+		}
+		if got, err := rddnet.ParamsForNet(params.Net); err != nil ||
+			got != params {
+
+			t.Errorf("%s: ParamsForNet: got %v, %v", params.Name,
+				got, err)
+		}
+		if err := params.VerifyGenesis(); err != nil {
+			t.Errorf("%s: VerifyGenesis: %v", params.Name, err)
+		}
+		if err := params.VerifyPorts(); err != nil {
+			t.Errorf("%s: VerifyPorts: %v", params.Name, err)
+		}
+		if params.PowLimitBits != 0x207fffff ||
+			params.GenesisBlock.Header.Bits != 0x207fffff {
+
+			t.Errorf("%s: got bits %#08x and genesis bits %#08x",
+				params.Name, params.PowLimitBits,
+				params.GenesisBlock.Header.Bits)
+		}
+	}
+
+	// No identifier may be shared with another network.
+	others := append([]*rddnet.Params{beta}, defaultNets...)
+	for _, other := range others {
+		if alpha.Net == other.Net {
+			t.Errorf("alpha shares its magic with %s", other.Name)
+		}
+		for _, port := range []uint16{alpha.P2PPort, alpha.RPCPort} {
+			if port == other.P2PPort || port == other.RPCPort {
+				t.Errorf("alpha shares the port %d with %s", port,
+					other.Name)
+			}
+		}
+		if *alpha.GenesisHash == *other.GenesisHash {
+			t.Errorf("alpha shares its genesis block with %s",
+				other.Name)
+		}
+		if alpha.Bech32HRPSegwit == other.Bech32HRPSegwit {
+			t.Errorf("alpha shares its bech32 hrp with %s",
+				other.Name)
+		}
+		if alpha.HDPrivateKeyID == other.HDPrivateKeyID ||
+			alpha.HDPublicKeyID == other.HDPublicKeyID {
+
+			t.Errorf("alpha shares an extended key magic with %s",
+				other.Name)
+		}
+		for _, id := range []byte{alpha.PubKeyHashAddrID,
+			alpha.ScriptHashAddrID, alpha.PrivateKeyID} {
+
+			if id == other.PubKeyHashAddrID ||
+				id == other.ScriptHashAddrID ||
+				id == other.PrivateKeyID {
+
+				t.Errorf("alpha shares the address magic %#02x "+
+					"with %s", id, other.Name)
+			}
+		}
+	}
+	if alpha.PubKeyHashAddrID == alpha.ScriptHashAddrID {
+		t.Error("alpha uses the same magic for P2PKH and P2SH")
+	}
+
+	// The name may only be registered once.
+	if _, err := rddnet.NewDevNet("DevNet-Alpha", register); err != rddnet.ErrDuplicateNetName {
+		t.Errorf("NewDevNet with duplicate name: got error %v want %v",
+			err, rddnet.ErrDuplicateNetName)
+	}
+	if _, err := rddnet.NewDevNet("", register); err != rddnet.ErrInvalidDevNetName {
+		t.Errorf("NewDevNet with empty name: got error %v want %v",
+			err, rddnet.ErrInvalidDevNetName)
+	}
+}
+
+// TestNewDevNetLongNames ensures development networks whose names only differ
+// after a long common prefix have different genesis blocks.
+func TestNewDevNetLongNames(t *testing.T) {
+	prefix := strings.Repeat("x", 70)
+	alpha, err := rddnet.NewDevNet(prefix+"-alpha",
+		rddnet.DevNetRegister(nil))
+	if err != nil {
+		t.Fatalf("NewDevNet: unexpected error %v", err)
+	}
+	beta, err := rddnet.NewDevNet(prefix+"-beta",
+		rddnet.DevNetRegister(nil))
+	if err != nil {
+		t.Fatalf("NewDevNet: unexpected error %v", err)
+	}
+	if *alpha.GenesisHash == *beta.GenesisHash {
+		t.Errorf("long names share the genesis block %v",
+			alpha.GenesisHash)
+	}
+	for _, params := range []*rddnet.Params{alpha, beta} {
+		if err := params.VerifyGenesis(); err != nil {
+			t.Errorf("%s: VerifyGenesis: %v", params.Name, err)
+		}
+	}
+}
+
+// TestNewDevNetOptions ensures development networks are derived
+// deterministically from their names and that the options are applied.
+func TestNewDevNetOptions(t *testing.T) {
+	a, err := rddnet.NewDevNet("devnet-unregistered",
+		rddnet.DevNetRegister(nil))
+	if err != nil {
+		t.Fatalf("NewDevNet: unexpected error %v", err)
+	}
+	b, err := rddnet.NewDevNet("devnet-unregistered",
+		rddnet.DevNetRegister(nil))
+	if err != nil {
+		t.Fatalf("NewDevNet: unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Error("NewDevNet is not deterministic")
+	}
+	if _, err := rddnet.ParamsForName(a.Name); err == nil {
+		t.Error("NewDevNet registered the network without a " +
+			"registration function")
+	}
+
+	var registered *rddnet.Params
+	timestamp := time.Unix(1500000000, 0)
+	params, err := rddnet.NewDevNet("devnet-custom",
+		rddnet.DevNetTimestamp(timestamp),
+		rddnet.DevNetRegister(func(p *rddnet.Params) error {
+			registered = p
+			return nil
+		}))
+	if err != nil {
+		t.Fatalf("NewDevNet: unexpected error %v", err)
+	}
+	if registered != params {
+		t.Error("NewDevNet did not call the registration function")
+	}
+	if !params.GenesisBlock.Header.Timestamp.Equal(timestamp) {
+		t.Errorf("genesis timestamp: got %v want %v",
+			params.GenesisBlock.Header.Timestamp, timestamp)
+	}
+	if err := params.VerifyGenesis(); err != nil {
+		t.Errorf("VerifyGenesis: %v", err)
+	}
+	if *params.GenesisHash == *a.GenesisHash {
+		t.Error("networks with different names share a genesis block")
+	}
+}
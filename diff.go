@@ -0,0 +1,468 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/reddcoin-project/rddwire"
+)
+
+// FieldClass describes what a network parameter field affects and therefore
+// how risky a change to it is.
+type FieldClass int
+
+// These constants define the classes of network parameter fields.
+const (
+	// FieldConsensus is a field which affects the validity of blocks.  A
+	// change to it forks the node off of nodes which do not share it.
+	FieldConsensus FieldClass = iota
+
+	// FieldWireMagic is a field which identifies the network on the wire.
+	// A change to it disconnects the node from peers which do not share
+	// it.
+	FieldWireMagic
+
+	// FieldAddressEncoding is a field which affects how addresses and keys
+	// are encoded.  A change to it makes existing addresses and keys
+	// invalid for the network.
+	FieldAddressEncoding
+
+	// FieldPolicy is a field which only affects local relay and mining
+	// policy.
+	FieldPolicy
+
+	// FieldCosmetic is a field which only affects how the network is named
+	// or reached and has no effect on consensus.
+	FieldCosmetic
+
+	// FieldWireProtocol is a field which affects the peer-to-peer protocol
+	// versions and features used with peers.  A change to it may
+	// disconnect the node from peers or change the messages exchanged
+	// with them without affecting consensus.
+	FieldWireProtocol
+
+	// FieldSync is a field which only affects how a node syncs a chain it
+	// would otherwise validate, such as the trusted header snapshots.  A
+	// change to it has no effect on consensus.
+	FieldSync
+)
+
+// fieldClassStrings is a map of field classes back to their constant names for
+// pretty printing.
+var fieldClassStrings = map[FieldClass]string{
+	FieldConsensus:       "FieldConsensus",
+	FieldWireMagic:       "FieldWireMagic",
+	FieldAddressEncoding: "FieldAddressEncoding",
+	FieldPolicy:          "FieldPolicy",
+	FieldCosmetic:        "FieldCosmetic",
+	FieldWireProtocol:    "FieldWireProtocol",
+	FieldSync:            "FieldSync",
+}
+
+// String returns the FieldClass in human-readable form.
+func (c FieldClass) String() string {
+	if s, ok := fieldClassStrings[c]; ok {
+		return s
+	}
+	return fmt.Sprintf("Unknown FieldClass (%d)", int(c))
+}
+
+// FieldDiff describes a single field which differs between two sets of network
+// parameters.
+type FieldDiff struct {
+	// Path is the path of the field from the Params struct, such as
+	// PowLimitBits, Checkpoints[1000], or
+	// GenesisBlock.Transactions[0].LockTime.  Checkpoints and header
+	// snapshots are indexed by height rather than by position.
+	Path string
+
+	// Old and New are the formatted values of the field in the first and
+	// second parameters respectively.  A value which is only present in
+	// one of the parameters, such as an added checkpoint, is formatted as
+	// the empty string in the other.
+	Old string
+	New string
+
+	// Class describes what the field affects.
+	Class FieldClass
+}
+
+// String returns the FieldDiff in human-readable form.
+func (d FieldDiff) String() string {
+	return fmt.Sprintf("%s (%v): %q -> %q", d.Path, d.Class, d.Old, d.New)
+}
+
+// differ accumulates the differences found by DiffParams.
+type differ struct {
+	diffs []FieldDiff
+}
+
+// add records a difference when the formatted values are not equal.
+func (d *differ) add(path string, class FieldClass, old, new string) {
+	if old != new {
+		d.diffs = append(d.diffs, FieldDiff{
+			Path:  path,
+			Old:   old,
+			New:   new,
+			Class: class,
+		})
+	}
+}
+
+// addf formats both values with the passed format and records a difference
+// when they are not equal.
+func (d *differ) addf(path string, class FieldClass, format string, old,
+	new interface{}) {
+
+	d.add(path, class, fmt.Sprintf(format, old), fmt.Sprintf(format, new))
+}
+
+// addByHeight records a difference of the passed class for every height at
+// which the passed values, keyed by height, differ.  Heights are reported in
+// ascending order.
+func (d *differ) addByHeight(path string, class FieldClass, a,
+	b map[int64]string) {
+
+	heights := make([]int64, 0, len(a)+len(b))
+	for height := range a {
+		heights = append(heights, height)
+	}
+	for height := range b {
+		if _, ok := a[height]; !ok {
+			heights = append(heights, height)
+		}
+	}
+	sort.Slice(heights, func(i, j int) bool {
+		return heights[i] < heights[j]
+	})
+	for _, height := range heights {
+		d.add(fmt.Sprintf("%s[%d]", path, height), class, a[height],
+			b[height])
+	}
+}
+
+// joinValue appends the value to the values already recorded for a key, which
+// only happens for parameters with duplicate heights.
+func joinValue(values, value string) string {
+	if values == "" {
+		return value
+	}
+	return values + "," + value
+}
+
+// formatHash returns the hash as a string or the empty string for a nil hash.
+func formatHash(hash *rddwire.ShaHash) string {
+	if hash == nil {
+		return ""
+	}
+	return hash.String()
+}
+
+// formatBigInt returns the integer in hex or the empty string for nil.
+func formatBigInt(n *big.Int) string {
+	if n == nil {
+		return ""
+	}
+	return fmt.Sprintf("%#x", n)
+}
+
+// diffTx records the differences between two genesis block transactions of
+// the networks with the parameters ap and bp.  Nil transactions are treated as
+// absent.  The timestamp of a transaction is only compared when it is
+// serialized, which is the case for the PoSV format.
+func (d *differ) diffTx(path string, ap, bp *Params, a, b *rddwire.MsgTx) {
+	if a == nil || b == nil {
+		format := func(p *Params, tx *rddwire.MsgTx) string {
+			if tx == nil {
+				return ""
+			}
+			return p.TxSha(tx).String()
+		}
+		d.add(path, FieldConsensus, format(ap, a), format(bp, b))
+		return
+	}
+
+	d.addf(path+".Version", FieldConsensus, "%d", a.Version, b.Version)
+
+	d.addf(path+".TxIn.len", FieldConsensus, "%d", len(a.TxIn), len(b.TxIn))
+	for i := 0; i < len(a.TxIn) || i < len(b.TxIn); i++ {
+		var oldIn, newIn rddwire.TxIn
+		if i < len(a.TxIn) && a.TxIn[i] != nil {
+			oldIn = *a.TxIn[i]
+		}
+		if i < len(b.TxIn) && b.TxIn[i] != nil {
+			newIn = *b.TxIn[i]
+		}
+		inPath := fmt.Sprintf("%s.TxIn[%d]", path, i)
+		d.add(inPath+".PreviousOutPoint", FieldConsensus,
+			fmt.Sprintf("%v:%d", oldIn.PreviousOutPoint.Hash,
+				oldIn.PreviousOutPoint.Index),
+			fmt.Sprintf("%v:%d", newIn.PreviousOutPoint.Hash,
+				newIn.PreviousOutPoint.Index))
+		d.add(inPath+".SignatureScript", FieldConsensus,
+			hex.EncodeToString(oldIn.SignatureScript),
+			hex.EncodeToString(newIn.SignatureScript))
+		d.addf(inPath+".Sequence", FieldConsensus, "%d",
+			oldIn.Sequence, newIn.Sequence)
+	}
+
+	d.addf(path+".TxOut.len", FieldConsensus, "%d", len(a.TxOut),
+		len(b.TxOut))
+	for i := 0; i < len(a.TxOut) || i < len(b.TxOut); i++ {
+		var oldOut, newOut rddwire.TxOut
+		if i < len(a.TxOut) && a.TxOut[i] != nil {
+			oldOut = *a.TxOut[i]
+		}
+		if i < len(b.TxOut) && b.TxOut[i] != nil {
+			newOut = *b.TxOut[i]
+		}
+		outPath := fmt.Sprintf("%s.TxOut[%d]", path, i)
+		d.addf(outPath+".Value", FieldConsensus, "%d", oldOut.Value,
+			newOut.Value)
+		d.add(outPath+".PkScript", FieldConsensus,
+			hex.EncodeToString(oldOut.PkScript),
+			hex.EncodeToString(newOut.PkScript))
+	}
+
+	d.addf(path+".LockTime", FieldConsensus, "%d", a.LockTime, b.LockTime)
+
+	formatTimestamp := func(p *Params, tx *rddwire.MsgTx) string {
+		if p.TxFormat(tx.Version) != TxFormatPoSV {
+			return ""
+		}
+		return fmt.Sprintf("%d", tx.Timestamp.Unix())
+	}
+	d.add(path+".Timestamp", FieldConsensus, formatTimestamp(ap, a),
+		formatTimestamp(bp, b))
+}
+
+// diffBlock records the differences between two genesis blocks of the
+// networks with the parameters ap and bp.
+func (d *differ) diffBlock(path string, ap, bp *Params, a, b *rddwire.MsgBlock) {
+	if a == nil || b == nil {
+		format := func(block *rddwire.MsgBlock) string {
+			if block == nil {
+				return ""
+			}
+			hash, err := block.BlockSha()
+			if err != nil {
+				return err.Error()
+			}
+			return hash.String()
+		}
+		d.add(path, FieldConsensus, format(a), format(b))
+		return
+	}
+
+	ah, bh := &a.Header, &b.Header
+	hdrPath := path + ".Header"
+	d.addf(hdrPath+".Version", FieldConsensus, "%d", ah.Version, bh.Version)
+	d.addf(hdrPath+".PrevBlock", FieldConsensus, "%v", ah.PrevBlock,
+		bh.PrevBlock)
+	d.addf(hdrPath+".MerkleRoot", FieldConsensus, "%v", ah.MerkleRoot,
+		bh.MerkleRoot)
+	d.addf(hdrPath+".Timestamp", FieldConsensus, "%d", ah.Timestamp.Unix(),
+		bh.Timestamp.Unix())
+	d.addf(hdrPath+".Bits", FieldConsensus, "%#08x", ah.Bits, bh.Bits)
+	d.addf(hdrPath+".Nonce", FieldConsensus, "%d", ah.Nonce, bh.Nonce)
+
+	d.addf(path+".Transactions.len", FieldConsensus, "%d",
+		len(a.Transactions), len(b.Transactions))
+	for i := 0; i < len(a.Transactions) || i < len(b.Transactions); i++ {
+		var oldTx, newTx *rddwire.MsgTx
+		if i < len(a.Transactions) {
+			oldTx = a.Transactions[i]
+		}
+		if i < len(b.Transactions) {
+			newTx = b.Transactions[i]
+		}
+		d.diffTx(fmt.Sprintf("%s.Transactions[%d]", path, i), ap, bp,
+			oldTx, newTx)
+	}
+}
+
+// DiffParams returns every field which differs between the two passed network
+// parameters along with how it is classified.  Checkpoints and header snapshots
+// are compared individually by height, with paths such as Checkpoints[1000],
+// and the genesis blocks are compared field by field, including every
+// serialized field of every transaction, so the exact change can be reported.
+// An empty result means the parameters are equivalent.
+//
+// This allows deployment tooling to detect changes to consensus-critical
+// fields, such as those introduced by upgrading this package or by loading
+// network parameters from configuration, before they take effect.
+func DiffParams(a, b *Params) []FieldDiff {
+	var d differ
+
+	d.add("Name", FieldCosmetic, a.Name, b.Name)
+	d.addf("Net", FieldWireMagic, "%#08x", uint32(a.Net), uint32(b.Net))
+	d.add("DefaultPort", FieldCosmetic, a.DefaultPort, b.DefaultPort)
+	d.addf("P2PPort", FieldCosmetic, "%d", a.P2PPort, b.P2PPort)
+	d.addf("RPCPort", FieldCosmetic, "%d", a.RPCPort, b.RPCPort)
+	d.addf("WSPort", FieldCosmetic, "%d", a.WSPort, b.WSPort)
+	d.add("Aliases", FieldCosmetic, strings.Join(a.Aliases, ","),
+		strings.Join(b.Aliases, ","))
+
+	// Chain parameters.
+	d.diffBlock("GenesisBlock", a, b, a.GenesisBlock, b.GenesisBlock)
+	d.add("GenesisHash", FieldConsensus, formatHash(a.GenesisHash),
+		formatHash(b.GenesisHash))
+	d.add("PowLimit", FieldConsensus, formatBigInt(a.PowLimit),
+		formatBigInt(b.PowLimit))
+	d.addf("PowLimitBits", FieldConsensus, "%#08x", a.PowLimitBits,
+		b.PowLimitBits)
+	d.addf("SubsidyHalvingInterval", FieldConsensus, "%d",
+		a.SubsidyHalvingInterval, b.SubsidyHalvingInterval)
+	d.addf("ResetMinDifficulty", FieldConsensus, "%v",
+		a.ResetMinDifficulty, b.ResetMinDifficulty)
+	d.addf("PoSVTxVersion", FieldConsensus, "%d", a.PoSVTxVersion,
+		b.PoSVTxVersion)
+	d.addf("CoinbaseMaturity", FieldConsensus, "%d", a.CoinbaseMaturity,
+		b.CoinbaseMaturity)
+	d.addf("CoinstakeMaturity", FieldConsensus, "%d", a.CoinstakeMaturity,
+		b.CoinstakeMaturity)
+	d.addf("MaxMoney", FieldConsensus, "%d", a.MaxMoney, b.MaxMoney)
+
+	// Block limits.
+	d.addf("BlockLimits.len", FieldConsensus, "%d", len(a.BlockLimits),
+		len(b.BlockLimits))
+	for i := 0; i < len(a.BlockLimits) || i < len(b.BlockLimits); i++ {
+		format := func(limits []BlockLimits) string {
+			if i >= len(limits) {
+				return ""
+			}
+			return fmt.Sprintf("%+v", limits[i])
+		}
+		d.add(fmt.Sprintf("BlockLimits[%d]", i), FieldConsensus,
+			format(a.BlockLimits), format(b.BlockLimits))
+	}
+
+	// Checkpoints and header snapshots are compared by height, so adding
+	// or removing one only reports that one.
+	formatCheckpoints := func(checkpoints []Checkpoint) map[int64]string {
+		m := make(map[int64]string, len(checkpoints))
+		for _, checkpoint := range checkpoints {
+			value := formatHash(checkpoint.Hash)
+			if value == "" {
+				value = "<nil>"
+			}
+			m[checkpoint.Height] = joinValue(m[checkpoint.Height],
+				value)
+		}
+		return m
+	}
+	d.addByHeight("Checkpoints", FieldConsensus,
+		formatCheckpoints(a.Checkpoints), formatCheckpoints(b.Checkpoints))
+	formatSnapshots := func(snapshots []HeaderSnapshot) map[int64]string {
+		m := make(map[int64]string, len(snapshots))
+		for i := range snapshots {
+			snapshot := &snapshots[i]
+			value := formatBigInt(snapshot.Work)
+			hash, err := snapshot.Header.BlockSha()
+			if err != nil {
+				value = err.Error()
+			} else {
+				value = fmt.Sprintf("%v:%s", hash, value)
+			}
+			m[snapshot.Height] = joinValue(m[snapshot.Height], value)
+		}
+		return m
+	}
+
+	// Header snapshots only let nodes skip downloading headers, so like
+	// Fingerprint they are not treated as consensus.
+	d.addByHeight("HeaderSnapshots", FieldSync,
+		formatSnapshots(a.HeaderSnapshots),
+		formatSnapshots(b.HeaderSnapshots))
+
+	// Checkpoint master public keys.
+	d.addf("CheckpointPubKeys.len", FieldConsensus, "%d",
+		len(a.CheckpointPubKeys), len(b.CheckpointPubKeys))
+	for i := 0; i < len(a.CheckpointPubKeys) || i < len(b.CheckpointPubKeys); i++ {
+		format := func(keys [][]byte) string {
+			if i >= len(keys) {
+				return ""
+			}
+			return hex.EncodeToString(keys[i])
+		}
+		d.add(fmt.Sprintf("CheckpointPubKeys[%d]", i), FieldConsensus,
+			format(a.CheckpointPubKeys), format(b.CheckpointPubKeys))
+	}
+
+	// Signet challenge.
+	d.add("SignetChallenge", FieldConsensus,
+		hex.EncodeToString(a.SignetChallenge),
+		hex.EncodeToString(b.SignetChallenge))
+
+	// BIP0034 upgrade windows.
+	d.addf("BlockV1RejectNumRequired", FieldConsensus, "%d",
+		a.BlockV1RejectNumRequired, b.BlockV1RejectNumRequired)
+	d.addf("BlockV1RejectNumToCheck", FieldConsensus, "%d",
+		a.BlockV1RejectNumToCheck, b.BlockV1RejectNumToCheck)
+	d.addf("CoinbaseBlockHeightNumRequired", FieldConsensus, "%d",
+		a.CoinbaseBlockHeightNumRequired,
+		b.CoinbaseBlockHeightNumRequired)
+	d.addf("CoinbaseBlockHeightNumToCheck", FieldConsensus, "%d",
+		a.CoinbaseBlockHeightNumToCheck, b.CoinbaseBlockHeightNumToCheck)
+
+	// Peer-to-peer protocol parameters.
+	d.addf("MinProtocolVersion", FieldWireProtocol, "%d", a.MinProtocolVersion,
+		b.MinProtocolVersion)
+	for _, feature := range sortedFeatures(a.FeatureVersions,
+		b.FeatureVersions) {
+
+		format := func(table map[ProtocolFeature]uint32) string {
+			version, ok := table[feature]
+			if !ok {
+				return ""
+			}
+			return fmt.Sprintf("%d", version)
+		}
+		d.add(fmt.Sprintf("FeatureVersions[%v]", feature),
+			FieldWireProtocol,
+			format(a.FeatureVersions), format(b.FeatureVersions))
+	}
+
+	// Default relay and mining policy.  The deprecated RelayNonStdTxs field
+	// mirrors the policy and is not reported separately.
+	ap, bp := &a.Policy, &b.Policy
+	d.addf("Policy.RelayNonStdTxs", FieldPolicy, "%v", ap.RelayNonStdTxs,
+		bp.RelayNonStdTxs)
+	d.addf("Policy.MinRelayTxFee", FieldPolicy, "%d", ap.MinRelayTxFee,
+		bp.MinRelayTxFee)
+	d.addf("Policy.DustThreshold", FieldPolicy, "%d", ap.DustThreshold,
+		bp.DustThreshold)
+	d.addf("Policy.MaxStandardTxSize", FieldPolicy, "%d",
+		ap.MaxStandardTxSize, bp.MaxStandardTxSize)
+	d.addf("Policy.MaxDataCarrierSize", FieldPolicy, "%d",
+		ap.MaxDataCarrierSize, bp.MaxDataCarrierSize)
+	d.addf("Policy.EnableRBF", FieldPolicy, "%v", ap.EnableRBF,
+		bp.EnableRBF)
+	d.addf("Policy.DefaultBlockMinSize", FieldPolicy, "%d",
+		ap.DefaultBlockMinSize, bp.DefaultBlockMinSize)
+
+	// Address and key encoding magics.
+	d.addf("PubKeyHashAddrID", FieldAddressEncoding, "%#02x",
+		a.PubKeyHashAddrID, b.PubKeyHashAddrID)
+	d.addf("ScriptHashAddrID", FieldAddressEncoding, "%#02x",
+		a.ScriptHashAddrID, b.ScriptHashAddrID)
+	d.addf("PrivateKeyID", FieldAddressEncoding, "%#02x", a.PrivateKeyID,
+		b.PrivateKeyID)
+	d.add("Bech32HRPSegwit", FieldAddressEncoding, a.Bech32HRPSegwit,
+		b.Bech32HRPSegwit)
+	d.addf("HDPrivateKeyID", FieldAddressEncoding, "%x", a.HDPrivateKeyID,
+		b.HDPrivateKeyID)
+	d.addf("HDPublicKeyID", FieldAddressEncoding, "%x", a.HDPublicKeyID,
+		b.HDPublicKeyID)
+	d.addf("HDCoinType", FieldAddressEncoding, "%d", a.HDCoinType,
+		b.HDCoinType)
+
+	return d.diffs
+}
@@ -0,0 +1,467 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/reddcoin-project/rddnet"
+	"github.com/reddcoin-project/rddwire"
+)
+
+// TestDiffParams ensures the differences between network parameters are
+// reported with the expected paths, values, and classes.
+func TestDiffParams(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(*rddnet.Params)
+		want   []rddnet.FieldDiff
+	}{
+		{
+			name:   "equal",
+			mutate: func(p *rddnet.Params) {},
+			want:   nil,
+		},
+		{
+			name: "cosmetic and policy",
+			mutate: func(p *rddnet.Params) {
+				p.Name = "renamed"
+				p.Policy.RelayNonStdTxs = true
+			},
+			want: []rddnet.FieldDiff{
+				{
+					Path:  "Name",
+					Old:   "mainnet",
+					New:   "renamed",
+					Class: rddnet.FieldCosmetic,
+				},
+				{
+					Path:  "Policy.RelayNonStdTxs",
+					Old:   "false",
+					New:   "true",
+					Class: rddnet.FieldPolicy,
+				},
+			},
+		},
+		{
+			name: "ports",
+			mutate: func(p *rddnet.Params) {
+				p.RPCPort = 1
+				p.WSPort = 2
+			},
+			want: []rddnet.FieldDiff{
+				{
+					Path:  "RPCPort",
+					Old:   "45443",
+					New:   "1",
+					Class: rddnet.FieldCosmetic,
+				},
+				{
+					Path:  "WSPort",
+					Old:   "0",
+					New:   "2",
+					Class: rddnet.FieldCosmetic,
+				},
+			},
+		},
+		{
+			name: "protocol versions",
+			mutate: func(p *rddnet.Params) {
+				p.MinProtocolVersion = 209
+				delete(p.FeatureVersions, rddnet.FeatureFeeFilter)
+				p.FeatureVersions[rddnet.FeatureSendHeaders] = 70003
+			},
+			want: []rddnet.FieldDiff{
+				{
+					Path:  "MinProtocolVersion",
+					Old:   "70002",
+					New:   "209",
+					Class: rddnet.FieldWireProtocol,
+				},
+				{
+					Path:  "FeatureVersions[FeatureSendHeaders]",
+					Old:   "70012",
+					New:   "70003",
+					Class: rddnet.FieldWireProtocol,
+				},
+				{
+					Path:  "FeatureVersions[FeatureFeeFilter]",
+					Old:   "70013",
+					New:   "",
+					Class: rddnet.FieldWireProtocol,
+				},
+			},
+		},
+		{
+			name: "wire magic and address encoding",
+			mutate: func(p *rddnet.Params) {
+				p.Net = 0x01020304
+				p.PubKeyHashAddrID = 0x00
+				p.HDPublicKeyID = [4]byte{0x04, 0x88, 0xb2, 0x1f}
+			},
+			want: []rddnet.FieldDiff{
+				{
+					Path:  "Net",
+					Old:   "0xdbb6c0fb",
+					New:   "0x01020304",
+					Class: rddnet.FieldWireMagic,
+				},
+				{
+					Path:  "PubKeyHashAddrID",
+					Old:   "0x3d",
+					New:   "0x00",
+					Class: rddnet.FieldAddressEncoding,
+				},
+				{
+					Path:  "HDPublicKeyID",
+					Old:   "0488b21e",
+					New:   "0488b21f",
+					Class: rddnet.FieldAddressEncoding,
+				},
+			},
+		},
+		{
+			name: "inserted checkpoint",
+			mutate: func(p *rddnet.Params) {
+				checkpoints := append([]rddnet.Checkpoint{},
+					p.Checkpoints[:2]...)
+				checkpoints = append(checkpoints,
+					rddnet.Checkpoint{Height: 1500,
+						Hash: &rddwire.ShaHash{0x01}})
+				p.Checkpoints = append(checkpoints,
+					p.Checkpoints[2:]...)
+			},
+			want: []rddnet.FieldDiff{
+				{
+					Path:  "Checkpoints[1500]",
+					Old:   "",
+					New:   "0000000000000000000000000000000000000000000000000000000000000001",
+					Class: rddnet.FieldConsensus,
+				},
+			},
+		},
+		{
+			name: "moved checkpoint",
+			mutate: func(p *rddnet.Params) {
+				p.Checkpoints[1].Height = 1001
+			},
+			want: []rddnet.FieldDiff{
+				{
+					Path:  "Checkpoints[1000]",
+					Old:   "9d849e078deac30d58372db898318186cf5073a7f0b109b4776393b21b7b4e5a",
+					New:   "",
+					Class: rddnet.FieldConsensus,
+				},
+				{
+					Path:  "Checkpoints[1001]",
+					Old:   "",
+					New:   "9d849e078deac30d58372db898318186cf5073a7f0b109b4776393b21b7b4e5a",
+					Class: rddnet.FieldConsensus,
+				},
+			},
+		},
+		{
+			name: "block limits",
+			mutate: func(p *rddnet.Params) {
+				p.BlockLimits = append(p.BlockLimits,
+					rddnet.BlockLimits{Height: 5000,
+						MaxSize: 4000000, MaxSigOps: 80000,
+						MaxWeight: 4000000})
+				p.Policy.MaxStandardTxSize = 400000
+			},
+			want: []rddnet.FieldDiff{
+				{
+					Path:  "BlockLimits.len",
+					Old:   "1",
+					New:   "2",
+					Class: rddnet.FieldConsensus,
+				},
+				{
+					Path:  "BlockLimits[1]",
+					Old:   "",
+					New:   "{Height:5000 MaxSize:4000000 MaxSigOps:80000 MaxWeight:4000000}",
+					Class: rddnet.FieldConsensus,
+				},
+				{
+					Path:  "Policy.MaxStandardTxSize",
+					Old:   "100000",
+					New:   "400000",
+					Class: rddnet.FieldPolicy,
+				},
+			},
+		},
+		{
+			name: "header snapshots",
+			mutate: func(p *rddnet.Params) {
+				p.HeaderSnapshots = nil
+			},
+			want: []rddnet.FieldDiff{
+				{
+					Path: "HeaderSnapshots[0]",
+					Old: rddnet.MainNetParams.GenesisHash.String() +
+						":0x100010",
+					New:   "",
+					Class: rddnet.FieldSync,
+				},
+			},
+		},
+		{
+			name: "checkpoint public keys",
+			mutate: func(p *rddnet.Params) {
+				p.CheckpointPubKeys = [][]byte{{0x02, 0xab}}
+			},
+			want: []rddnet.FieldDiff{
+				{
+					Path:  "CheckpointPubKeys.len",
+					Old:   "0",
+					New:   "1",
+					Class: rddnet.FieldConsensus,
+				},
+				{
+					Path:  "CheckpointPubKeys[0]",
+					Old:   "",
+					New:   "02ab",
+					Class: rddnet.FieldConsensus,
+				},
+			},
+		},
+		{
+			name: "signet challenge",
+			mutate: func(p *rddnet.Params) {
+				p.SignetChallenge = []byte{0x51}
+			},
+			want: []rddnet.FieldDiff{
+				{
+					Path:  "SignetChallenge",
+					Old:   "",
+					New:   "51",
+					Class: rddnet.FieldConsensus,
+				},
+			},
+		},
+		{
+			name: "legacy transaction timestamp",
+			mutate: func(p *rddnet.Params) {
+				tx := p.GenesisBlock.Transactions[0]
+				tx.Timestamp = tx.Timestamp.Add(time.Hour)
+			},
+			want: nil,
+		},
+		{
+			name: "posv transaction timestamp",
+			mutate: func(p *rddnet.Params) {
+				p.PoSVTxVersion = 1
+				tx := p.GenesisBlock.Transactions[0]
+				tx.Timestamp = time.Unix(1390095618, 0)
+			},
+			want: []rddnet.FieldDiff{
+				{
+					Path:  "GenesisBlock.Transactions[0].Timestamp",
+					Old:   "",
+					New:   "1390095618",
+					Class: rddnet.FieldConsensus,
+				},
+				{
+					Path:  "PoSVTxVersion",
+					Old:   "2",
+					New:   "1",
+					Class: rddnet.FieldConsensus,
+				},
+			},
+		},
+		{
+			name: "genesis transaction",
+			mutate: func(p *rddnet.Params) {
+				tx := p.GenesisBlock.Transactions[0]
+				tx.TxIn[0].Sequence = 0
+				tx.TxOut[0].Value = 1
+				tx.TxOut = append(tx.TxOut, &rddwire.TxOut{
+					Value:    2,
+					PkScript: []byte{0x51},
+				})
+				p.GenesisBlock.Header.Nonce = 0
+			},
+			want: []rddnet.FieldDiff{
+				{
+					Path:  "GenesisBlock.Header.Nonce",
+					Old:   "222583475",
+					New:   "0",
+					Class: rddnet.FieldConsensus,
+				},
+				{
+					Path:  "GenesisBlock.Transactions[0].TxIn[0].Sequence",
+					Old:   "4294967295",
+					New:   "0",
+					Class: rddnet.FieldConsensus,
+				},
+				{
+					Path:  "GenesisBlock.Transactions[0].TxOut.len",
+					Old:   "1",
+					New:   "2",
+					Class: rddnet.FieldConsensus,
+				},
+				{
+					Path:  "GenesisBlock.Transactions[0].TxOut[0].Value",
+					Old:   "1000000000000",
+					New:   "1",
+					Class: rddnet.FieldConsensus,
+				},
+				{
+					Path:  "GenesisBlock.Transactions[0].TxOut[1].Value",
+					Old:   "0",
+					New:   "2",
+					Class: rddnet.FieldConsensus,
+				},
+				{
+					Path:  "GenesisBlock.Transactions[0].TxOut[1].PkScript",
+					Old:   "",
+					New:   "51",
+					Class: rddnet.FieldConsensus,
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		params := rddnet.MainNetParams.Clone()
+		test.mutate(params)
+		got := rddnet.DiffParams(&rddnet.MainNetParams, params)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: mismatched diff:\ngot  %v\nwant %v",
+				test.name, got, test.want)
+		}
+	}
+
+	// The built-in networks differ in fields of every class.
+	classes := make(map[rddnet.FieldClass]bool)
+	diffs := rddnet.DiffParams(&rddnet.MainNetParams, &rddnet.TestNet3Params)
+	for _, diff := range diffs {
+		classes[diff.Class] = true
+	}
+	for _, class := range []rddnet.FieldClass{rddnet.FieldConsensus,
+		rddnet.FieldWireMagic, rddnet.FieldAddressEncoding,
+		rddnet.FieldPolicy, rddnet.FieldCosmetic} {
+
+		if !classes[class] {
+			t.Errorf("mainnet to testnet3: no %v differences", class)
+		}
+	}
+}
+
+// mutateValue changes the passed settable value so it differs from its
+// current value and returns the paths of the changed fields relative to it.
+// Structs have every field changed.
+func mutateValue(v reflect.Value) []string {
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(!v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		v.SetInt(v.Int() + 1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		v.SetUint(v.Uint() + 1)
+	case reflect.String:
+		v.SetString(v.String() + "x")
+	case reflect.Array:
+		mutateValue(v.Index(0))
+	case reflect.Ptr, reflect.Map:
+		if v.IsNil() {
+			panic("mutateValue: nil " + v.Type().String())
+		}
+		v.Set(reflect.Zero(v.Type()))
+	case reflect.Slice:
+		if v.Len() > 0 {
+			v.Set(v.Slice(0, v.Len()-1))
+			break
+		}
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if elem.Kind() == reflect.Slice {
+			elem = reflect.MakeSlice(elem.Type(), 1, 1)
+		}
+		v.Set(reflect.Append(v, elem))
+	case reflect.Struct:
+		var paths []string
+		for i := 0; i < v.NumField(); i++ {
+			for _, path := range mutateValue(v.Field(i)) {
+				paths = append(paths, joinPath(v.Type().Field(i).Name, path))
+			}
+		}
+		return paths
+	default:
+		panic("mutateValue: unhandled kind " + v.Kind().String())
+	}
+	return []string{""}
+}
+
+// joinPath joins the name of a field with the path of a field inside it.
+func joinPath(name, path string) string {
+	if path == "" {
+		return name
+	}
+	return name + "." + path
+}
+
+// TestDiffParamsFields ensures DiffParams reports a change to every field of
+// Params, including fields added after it was written.  Each field of a clone
+// of the main network parameters is changed on its own, and a difference must
+// be reported for that field.
+func TestDiffParamsFields(t *testing.T) {
+	// RelayNonStdTxs is a deprecated mirror of Policy.RelayNonStdTxs,
+	// which is reported instead.
+	skip := map[string]bool{"RelayNonStdTxs": true}
+
+	typ := reflect.TypeOf(rddnet.Params{})
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if skip[field.Name] {
+			continue
+		}
+
+		params := rddnet.MainNetParams.Clone()
+		v := reflect.ValueOf(params).Elem().Field(i)
+		if v.Kind() == reflect.Struct {
+			// Change the fields of structs one at a time.
+			for j := 0; j < v.NumField(); j++ {
+				params := rddnet.MainNetParams.Clone()
+				sub := reflect.ValueOf(params).Elem().Field(i).Field(j)
+				path := field.Name + "." + v.Type().Field(j).Name
+				mutateValue(sub)
+				checkDiffReported(t, path, params)
+			}
+			continue
+		}
+		mutateValue(v)
+		checkDiffReported(t, field.Name, params)
+	}
+}
+
+// checkDiffReported ensures DiffParams reports a difference between the main
+// network parameters and the passed parameters at the passed path or inside
+// it.  Fields left out of Fingerprint must not be reported as consensus
+// differences.
+func checkDiffReported(t *testing.T, path string, params *rddnet.Params) {
+	sameChain := params.Fingerprint() == rddnet.MainNetParams.Fingerprint()
+	var reported bool
+	for _, diff := range rddnet.DiffParams(&rddnet.MainNetParams, params) {
+		if diff.Path != path && !strings.HasPrefix(diff.Path, path+".") &&
+			!strings.HasPrefix(diff.Path, path+"[") {
+
+			continue
+		}
+		reported = true
+		if sameChain && diff.Class == rddnet.FieldConsensus {
+			t.Errorf("DiffParams reports %s as a consensus "+
+				"difference but it does not change the "+
+				"fingerprint", diff.Path)
+		}
+	}
+	if !reported {
+		t.Errorf("DiffParams does not report a change to %s", path)
+	}
+}
@@ -55,6 +55,36 @@
 //          fmt.Println(addr)
 //  }
 //
+// Applications supporting more than two networks may instead use a NetworkFlag,
+// which selects any default or registered network by name, or by an alias such
+// as testnet, and reports the valid choices when given an unknown name:
+//
+//  var netFlag = rddnet.NewNetworkFlag(&rddnet.MainNetParams)
+//
+//  func main() {
+//          flag.Var(netFlag, "net", "the Reddcoin network to operate on")
+//          flag.Parse()
+//
+//          netParams := netFlag.Params
+//          // ...
+//  }
+//
+// The relay and mining policy defaults of a network, such as whether non
+// standard transactions are relayed, are held in Params.Policy.  The
+// Params.RelayNonStdTxs field which predates Policy is deprecated but still
+// mirrors Policy.RelayNonStdTxs on every default network and on parameters
+// returned by WithPolicy.  To migrate, read Policy.RelayNonStdTxs instead of
+// the old field and override the policy with WithPolicy rather than by
+// modifying the parameters.  Custom networks which set a policy must set both
+// fields to the same value until the old field is removed, since Register
+// rejects networks where they disagree:
+//
+//  params.Policy.RelayNonStdTxs = true
+//  params.RelayNonStdTxs = true // deprecated
+//
+// Custom networks which only set the old field keep working, since Register
+// fills the policy of networks without one from it.
+//
 // If an application does not use one of the three standard Bitcoin networks,
 // a new Params struct may be created which defines the parameters for the
 // non-standard network.  As a general rule of thumb, all network parameters
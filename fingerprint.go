@@ -0,0 +1,158 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"math/big"
+
+	"github.com/reddcoin-project/rddwire"
+)
+
+// fingerprintVersion identifies the serialization used by Fingerprint.  It
+// must be bumped whenever the serialization changes so fingerprints created by
+// different versions of this package never compare equal by accident.
+const fingerprintVersion = 1
+
+// fingerprintWriter builds the canonical serialization hashed by Fingerprint.
+// Every variable length value is prefixed with its length so no two different
+// sets of parameters serialize to the same bytes.
+type fingerprintWriter struct {
+	buf bytes.Buffer
+}
+
+func (w *fingerprintWriter) writeUint64(v uint64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], v)
+	w.buf.Write(b[:])
+}
+
+func (w *fingerprintWriter) writeBool(v bool) {
+	if v {
+		w.buf.WriteByte(1)
+		return
+	}
+	w.buf.WriteByte(0)
+}
+
+func (w *fingerprintWriter) writeBytes(b []byte) {
+	w.writeUint64(uint64(len(b)))
+	w.buf.Write(b)
+}
+
+func (w *fingerprintWriter) writeHash(hash *rddwire.ShaHash) {
+	if hash == nil {
+		w.writeBytes(nil)
+		return
+	}
+	w.writeBytes(hash[:])
+}
+
+func (w *fingerprintWriter) writeBigInt(n *big.Int) {
+	if n == nil {
+		w.writeBool(false)
+		return
+	}
+	w.writeBool(true)
+	w.writeBool(n.Sign() < 0)
+	w.writeBytes(n.Bytes())
+}
+
+func (w *fingerprintWriter) writeBlock(block *rddwire.MsgBlock) {
+	if block == nil {
+		w.writeBool(false)
+		return
+	}
+	var buf bytes.Buffer
+	if err := block.Serialize(&buf); err != nil {
+		// Serializing to a bytes.Buffer can only fail for blocks
+		// which could never be sent on the wire, so include the error
+		// rather than a partial serialization.
+		w.writeBool(false)
+		w.writeBytes([]byte(err.Error()))
+		return
+	}
+	w.writeBool(true)
+	w.writeBytes(buf.Bytes())
+}
+
+// Fingerprint returns a SHA256 hash of a canonical serialization of every
+// field of the parameters which must agree between nodes for them to follow
+// the same chain and exchange addresses and keys: the network magic, the
+// genesis block and its hash, the proof of work limits, the transaction
+// formats, the coinbase and coinstake maturities, the maximum amount, the block
+// limits, the checkpoints and checkpoint master keys, the BIP0034 upgrade
+// windows, and the address and key encoding magics.
+//
+// Cosmetic and local fields such as Name, Aliases, DefaultPort, and Policy are
+// not included, nor are the protocol version gates, which only affect which
+// peers may connect and what they negotiate.  Header snapshots are not
+// included either since they only let a node skip downloading headers of a
+// chain it would otherwise validate, and new releases add snapshots without
+// changing the chain.  This lets two nodes compare fingerprints to find out
+// whether they run the same network regardless of how it is named or
+// configured locally.
+func (p *Params) Fingerprint() [32]byte {
+	var w fingerprintWriter
+	w.writeUint64(fingerprintVersion)
+
+	// Wire magic.
+	w.writeUint64(uint64(p.Net))
+
+	// Chain parameters.
+	w.writeBlock(p.GenesisBlock)
+	w.writeHash(p.GenesisHash)
+	w.writeBigInt(p.PowLimit)
+	w.writeUint64(uint64(p.PowLimitBits))
+	w.writeUint64(uint64(p.SubsidyHalvingInterval))
+	w.writeBool(p.ResetMinDifficulty)
+	w.writeUint64(uint64(p.PoSVTxVersion))
+	w.writeUint64(uint64(p.CoinbaseMaturity))
+	w.writeUint64(uint64(p.CoinstakeMaturity))
+	w.writeUint64(uint64(p.MaxMoney))
+
+	// Block limits.
+	w.writeUint64(uint64(len(p.BlockLimits)))
+	for _, limits := range p.BlockLimits {
+		w.writeUint64(uint64(limits.Height))
+		w.writeUint64(uint64(limits.MaxSize))
+		w.writeUint64(uint64(limits.MaxSigOps))
+		w.writeUint64(uint64(limits.MaxWeight))
+	}
+
+	// Checkpoints.
+	w.writeUint64(uint64(len(p.Checkpoints)))
+	for _, checkpoint := range p.Checkpoints {
+		w.writeUint64(uint64(checkpoint.Height))
+		w.writeHash(checkpoint.Hash)
+	}
+
+	// Checkpoint master public keys.
+	w.writeUint64(uint64(len(p.CheckpointPubKeys)))
+	for _, key := range p.CheckpointPubKeys {
+		w.writeBytes(key)
+	}
+
+	// Signet challenge.
+	w.writeBytes(p.SignetChallenge)
+
+	// BIP0034 upgrade windows.
+	w.writeUint64(p.BlockV1RejectNumRequired)
+	w.writeUint64(p.BlockV1RejectNumToCheck)
+	w.writeUint64(p.CoinbaseBlockHeightNumRequired)
+	w.writeUint64(p.CoinbaseBlockHeightNumToCheck)
+
+	// Address and key encoding magics.
+	w.writeBytes([]byte{p.PubKeyHashAddrID, p.ScriptHashAddrID,
+		p.PrivateKeyID})
+	w.writeBytes([]byte(p.Bech32HRPSegwit))
+	w.writeBytes(p.HDPrivateKeyID[:])
+	w.writeBytes(p.HDPublicKeyID[:])
+	w.writeUint64(uint64(p.HDCoinType))
+
+	return sha256.Sum256(w.buf.Bytes())
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/reddcoin-project/rddnet"
+)
+
+// TestFingerprint ensures fingerprints are deterministic, differ between the
+// default networks, ignore cosmetic fields, and change with every consensus
+// field.
+func TestFingerprint(t *testing.T) {
+	nets := []*rddnet.Params{
+		&rddnet.MainNetParams,
+		&rddnet.RegressionNetParams,
+		&rddnet.TestNet3Params,
+		&rddnet.SimNetParams,
+	}
+	seen := make(map[[32]byte]string)
+	for _, params := range nets {
+		fp := params.Fingerprint()
+		if other, ok := seen[fp]; ok {
+			t.Errorf("%s: fingerprint collides with %s", params.Name,
+				other)
+		}
+		seen[fp] = params.Name
+
+		if clone := params.Clone(); clone.Fingerprint() != fp {
+			t.Errorf("%s: fingerprint of clone differs", params.Name)
+		}
+	}
+
+	base := rddnet.MainNetParams.Fingerprint()
+	tests := []struct {
+		name    string
+		mutate  func(*rddnet.Params)
+		changes bool
+	}{
+		{"name", func(p *rddnet.Params) { p.Name = "other" }, false},
+		{"default port", func(p *rddnet.Params) { p.DefaultPort = "1" }, false},
+		{"relay nonstd", func(p *rddnet.Params) { p.Policy.RelayNonStdTxs = true }, false},
+		{"net", func(p *rddnet.Params) { p.Net++ }, true},
+		{"genesis nonce", func(p *rddnet.Params) { p.GenesisBlock.Header.Nonce++ }, true},
+		{"genesis tx", func(p *rddnet.Params) {
+			p.GenesisBlock.Transactions[0].TxOut[0].Value++
+		}, true},
+		{"genesis hash", func(p *rddnet.Params) { p.GenesisHash[0] ^= 1 }, true},
+		{"pow limit", func(p *rddnet.Params) { p.PowLimit.Rsh(p.PowLimit, 1) }, true},
+		{"pow limit bits", func(p *rddnet.Params) { p.PowLimitBits++ }, true},
+		{"subsidy halving", func(p *rddnet.Params) { p.SubsidyHalvingInterval++ }, true},
+		{"reset min difficulty", func(p *rddnet.Params) { p.ResetMinDifficulty = true }, true},
+		{"posv tx version", func(p *rddnet.Params) { p.PoSVTxVersion++ }, true},
+		{"coinbase maturity", func(p *rddnet.Params) { p.CoinbaseMaturity++ }, true},
+		{"coinstake maturity", func(p *rddnet.Params) { p.CoinstakeMaturity++ }, true},
+		{"max money", func(p *rddnet.Params) { p.MaxMoney-- }, true},
+		{"min relay fee", func(p *rddnet.Params) { p.Policy.MinRelayTxFee++ }, false},
+		{"dust threshold", func(p *rddnet.Params) { p.Policy.DustThreshold++ }, false},
+		{"rbf", func(p *rddnet.Params) { p.Policy.EnableRBF = true }, false},
+		{"block size", func(p *rddnet.Params) { p.BlockLimits[0].MaxSize++ }, true},
+		{"block weight", func(p *rddnet.Params) { p.BlockLimits[0].MaxWeight = 4000000 }, true},
+		{"block limits added", func(p *rddnet.Params) {
+			p.BlockLimits = append(p.BlockLimits, p.BlockLimits[0])
+			p.BlockLimits[1].Height = 1000
+		}, true},
+		{"checkpoint height", func(p *rddnet.Params) { p.Checkpoints[0].Height++ }, true},
+		{"checkpoint hash", func(p *rddnet.Params) { p.Checkpoints[0].Hash[0] ^= 1 }, true},
+		{"checkpoint removed", func(p *rddnet.Params) {
+			p.Checkpoints = p.Checkpoints[:len(p.Checkpoints)-1]
+		}, true},
+		{"header snapshot added", func(p *rddnet.Params) {
+			p.HeaderSnapshots = []rddnet.HeaderSnapshot{{
+				Header: p.GenesisBlock.Header,
+				Work:   big.NewInt(1),
+			}}
+		}, false},
+		{"checkpoint key added", func(p *rddnet.Params) {
+			p.CheckpointPubKeys = [][]byte{{0x02, 0x01}}
+		}, true},
+		{"signet challenge", func(p *rddnet.Params) { p.SignetChallenge = []byte{0x51} }, true},
+		{"v1 reject required", func(p *rddnet.Params) { p.BlockV1RejectNumRequired++ }, true},
+		{"v1 reject to check", func(p *rddnet.Params) { p.BlockV1RejectNumToCheck++ }, true},
+		{"height required", func(p *rddnet.Params) { p.CoinbaseBlockHeightNumRequired++ }, true},
+		{"height to check", func(p *rddnet.Params) { p.CoinbaseBlockHeightNumToCheck++ }, true},
+		{"p2pkh magic", func(p *rddnet.Params) { p.PubKeyHashAddrID++ }, true},
+		{"p2sh magic", func(p *rddnet.Params) { p.ScriptHashAddrID++ }, true},
+		{"wif magic", func(p *rddnet.Params) { p.PrivateKeyID++ }, true},
+		{"bech32 hrp", func(p *rddnet.Params) { p.Bech32HRPSegwit = "rd" }, true},
+		{"hd private magic", func(p *rddnet.Params) { p.HDPrivateKeyID[3]++ }, true},
+		{"hd public magic", func(p *rddnet.Params) { p.HDPublicKeyID[3]++ }, true},
+		{"hd coin type", func(p *rddnet.Params) { p.HDCoinType++ }, true},
+	}
+	for _, test := range tests {
+		params := rddnet.MainNetParams.Clone()
+		test.mutate(params)
+		changed := params.Fingerprint() != base
+		if changed != test.changes {
+			t.Errorf("%s: fingerprint changed %v, want %v", test.name,
+				changed, test.changes)
+		}
+	}
+
+	if rddnet.MainNetParams.Fingerprint() != base {
+		t.Errorf("mainnet fingerprint changed by mutating clones")
+	}
+}
@@ -11,7 +11,9 @@ import (
 )
 
 // genesisCoinbaseTxBtc is the coinbase transaction for the genesis blocks for
-// the main network, regression test network, and test network (version 3).
+// the regression test network, test network (version 3), and simulation test
+// network.  It is the coinbase transaction of the bitcoin genesis block and, as
+// a version 1 transaction, uses the legacy format without a timestamp.
 var genesisCoinbaseTxBtc = rddwire.MsgTx{
 	Version: 1,
 	TxIn: []*rddwire.TxIn{
@@ -54,8 +56,9 @@ var genesisCoinbaseTxBtc = rddwire.MsgTx{
 	LockTime: 0,
 }
 
-// genesisCoinbaseTx is the coinbase transaction for the genesis blocks for
-// the main network, regression test network, and test network (version 3).
+// genesisCoinbaseTx is the coinbase transaction for the genesis block for the
+// main network.  It is a version 1 transaction, so it uses the legacy format
+// and its PoSV timestamp is not serialized or hashed.
 var genesisCoinbaseTx = rddwire.MsgTx{
 	Version: 1,
 	TxIn: []*rddwire.TxIn{
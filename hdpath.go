@@ -0,0 +1,182 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// HardenedKeyStart is the index at which a hardened key starts.  Each extended
+// key has 2^31 normal child keys and 2^31 hardened child keys.  Thus the range
+// for normal child keys is [0, 2^31 - 1] and the range for hardened child keys
+// is [2^31, 2^32 - 1].
+const HardenedKeyStart uint32 = 0x80000000 // 2^31
+
+// These constants define the purpose field (the first level below the master
+// key) of the standard hierarchical deterministic wallet structures.
+const (
+	// BIP0044Purpose is the purpose for pay-to-pubkey-hash accounts as
+	// defined by BIP0044.
+	BIP0044Purpose uint32 = 44
+
+	// BIP0049Purpose is the purpose for pay-to-witness-pubkey-hash nested
+	// in pay-to-script-hash accounts as defined by BIP0049.
+	BIP0049Purpose uint32 = 49
+
+	// BIP0084Purpose is the purpose for native pay-to-witness-pubkey-hash
+	// accounts as defined by BIP0084.
+	BIP0084Purpose uint32 = 84
+)
+
+var (
+	// ErrInvalidDerivationPath describes an error where a derivation path
+	// string is not of the form m/a/b'/c... or contains an index that is
+	// out of range.
+	ErrInvalidDerivationPath = errors.New("invalid hd derivation path")
+
+	// ErrHDCoinTypeMismatch describes an error where a BIP0044 style
+	// derivation path contains a coin type which does not match the
+	// HDCoinType of the network it is being used on.
+	ErrHDCoinTypeMismatch = errors.New("hd derivation path coin type " +
+		"does not match network")
+)
+
+// Hardened returns the hardened form of the passed child index.
+func Hardened(index uint32) uint32 {
+	return index | HardenedKeyStart
+}
+
+// IsHardened returns whether the passed child index is a hardened index.
+func IsHardened(index uint32) bool {
+	return index >= HardenedKeyStart
+}
+
+// DerivationPath is a hierarchical deterministic key derivation path relative
+// to a master key.  Each element is a child index where hardened children have
+// the HardenedKeyStart bit set.  The empty path refers to the master key.
+type DerivationPath []uint32
+
+// ParseDerivationPath parses a derivation path such as m/44'/4'/0'/0/1 into a
+// DerivationPath.  Hardened indexes may be marked with a trailing ', h, or H.
+// The leading m is required.
+func ParseDerivationPath(path string) (DerivationPath, error) {
+	elems := strings.Split(path, "/")
+	if elems[0] != "m" {
+		return nil, ErrInvalidDerivationPath
+	}
+
+	dp := make(DerivationPath, 0, len(elems)-1)
+	for _, elem := range elems[1:] {
+		hardened := false
+		switch {
+		case strings.HasSuffix(elem, "'"), strings.HasSuffix(elem, "h"),
+			strings.HasSuffix(elem, "H"):
+			hardened = true
+			elem = elem[:len(elem)-1]
+		}
+
+		// Reject signs, whitespace, and other forms ParseUint would
+		// otherwise happily accept.
+		if elem == "" || elem[0] < '0' || elem[0] > '9' {
+			return nil, ErrInvalidDerivationPath
+		}
+		index, err := strconv.ParseUint(elem, 10, 32)
+		if err != nil || uint32(index) >= HardenedKeyStart {
+			return nil, ErrInvalidDerivationPath
+		}
+		if hardened {
+			index = uint64(Hardened(uint32(index)))
+		}
+		dp = append(dp, uint32(index))
+	}
+
+	return dp, nil
+}
+
+// String returns the derivation path in the m/44'/4'/0'/0/1 notation.
+func (dp DerivationPath) String() string {
+	buf := make([]byte, 0, 1+len(dp)*4)
+	buf = append(buf, 'm')
+	for _, index := range dp {
+		buf = append(buf, '/')
+		buf = strconv.AppendUint(buf, uint64(index&^HardenedKeyStart), 10)
+		if IsHardened(index) {
+			buf = append(buf, '\'')
+		}
+	}
+	return string(buf)
+}
+
+// Child returns a new derivation path which extends the receiver with the
+// passed child index.  The receiver is not modified.
+func (dp DerivationPath) Child(index uint32) DerivationPath {
+	child := make(DerivationPath, len(dp), len(dp)+1)
+	copy(child, dp)
+	return append(child, index)
+}
+
+// accountPath returns the m/purpose'/coin_type'/account' path for the network.
+func (p *Params) accountPath(purpose, account uint32) DerivationPath {
+	return DerivationPath{
+		Hardened(purpose),
+		Hardened(p.HDCoinType),
+		Hardened(account),
+	}
+}
+
+// BIP44Account returns the BIP0044 account path m/44'/coin_type'/account' with
+// the coin type of the network.  Address paths are derived from it by
+// appending the change and address index with Child.
+func (p *Params) BIP44Account(account uint32) DerivationPath {
+	return p.accountPath(BIP0044Purpose, account)
+}
+
+// BIP49Account returns the BIP0049 account path m/49'/coin_type'/account' with
+// the coin type of the network.
+func (p *Params) BIP49Account(account uint32) DerivationPath {
+	return p.accountPath(BIP0049Purpose, account)
+}
+
+// BIP84Account returns the BIP0084 account path m/84'/coin_type'/account' with
+// the coin type of the network.
+func (p *Params) BIP84Account(account uint32) DerivationPath {
+	return p.accountPath(BIP0084Purpose, account)
+}
+
+// CheckDerivationPath returns ErrHDCoinTypeMismatch when the passed path uses
+// one of the BIP0044, BIP0049, or BIP0084 purposes and its coin type is not the
+// hardened HDCoinType of the network.  Paths with other purposes are not
+// checked.
+func (p *Params) CheckDerivationPath(dp DerivationPath) error {
+	if len(dp) < 2 {
+		return nil
+	}
+	switch dp[0] {
+	case Hardened(BIP0044Purpose), Hardened(BIP0049Purpose),
+		Hardened(BIP0084Purpose):
+	default:
+		return nil
+	}
+	if dp[1] != Hardened(p.HDCoinType) {
+		return ErrHDCoinTypeMismatch
+	}
+	return nil
+}
+
+// ParseDerivationPath parses the passed path string like the package level
+// ParseDerivationPath and additionally ensures its coin type matches the
+// network by way of CheckDerivationPath.
+func (p *Params) ParseDerivationPath(path string) (DerivationPath, error) {
+	dp, err := ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.CheckDerivationPath(dp); err != nil {
+		return nil, err
+	}
+	return dp, nil
+}
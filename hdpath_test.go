@@ -0,0 +1,184 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/reddcoin-project/rddnet"
+)
+
+// TestParseDerivationPath ensures derivation path strings are parsed into the
+// expected child indexes and formatted back into their canonical form.
+func TestParseDerivationPath(t *testing.T) {
+	h := rddnet.Hardened
+	tests := []struct {
+		name string
+		in   string
+		want rddnet.DerivationPath
+		str  string
+		err  error
+	}{
+		{
+			name: "master",
+			in:   "m",
+			want: rddnet.DerivationPath{},
+			str:  "m",
+		},
+		{
+			name: "bip44 address",
+			in:   "m/44'/4'/0'/0/1",
+			want: rddnet.DerivationPath{h(44), h(4), h(0), 0, 1},
+			str:  "m/44'/4'/0'/0/1",
+		},
+		{
+			name: "h hardened markers",
+			in:   "m/84h/1H/2147483647'",
+			want: rddnet.DerivationPath{h(84), h(1), h(2147483647)},
+			str:  "m/84'/1'/2147483647'",
+		},
+		{
+			name: "missing master",
+			in:   "44'/4'",
+			err:  rddnet.ErrInvalidDerivationPath,
+		},
+		{
+			name: "empty element",
+			in:   "m/44'//0",
+			err:  rddnet.ErrInvalidDerivationPath,
+		},
+		{
+			name: "trailing slash",
+			in:   "m/44'/",
+			err:  rddnet.ErrInvalidDerivationPath,
+		},
+		{
+			name: "index already hardened",
+			in:   "m/2147483648",
+			err:  rddnet.ErrInvalidDerivationPath,
+		},
+		{
+			name: "signed index",
+			in:   "m/+1",
+			err:  rddnet.ErrInvalidDerivationPath,
+		},
+		{
+			name: "double hardened marker",
+			in:   "m/1''",
+			err:  rddnet.ErrInvalidDerivationPath,
+		},
+	}
+
+	for _, test := range tests {
+		dp, err := rddnet.ParseDerivationPath(test.in)
+		if err != test.err {
+			t.Errorf("%s: unexpected error: got %v, want %v", test.name,
+				err, test.err)
+			continue
+		}
+		if test.err != nil {
+			continue
+		}
+		if !reflect.DeepEqual(dp, test.want) {
+			t.Errorf("%s: mismatched path: got %v, want %v", test.name,
+				[]uint32(dp), []uint32(test.want))
+		}
+		if got := dp.String(); got != test.str {
+			t.Errorf("%s: mismatched string: got %s, want %s",
+				test.name, got, test.str)
+		}
+	}
+}
+
+// TestAccountPaths ensures the account path constructors insert the coin type
+// of each network and that paths are checked against it.
+func TestAccountPaths(t *testing.T) {
+	tests := []struct {
+		name   string
+		params *rddnet.Params
+		path   func(uint32) rddnet.DerivationPath
+		want   string
+	}{
+		{
+			name:   "mainnet bip44",
+			params: &rddnet.MainNetParams,
+			path:   rddnet.MainNetParams.BIP44Account,
+			want:   "m/44'/4'/7'",
+		},
+		{
+			name:   "mainnet bip49",
+			params: &rddnet.MainNetParams,
+			path:   rddnet.MainNetParams.BIP49Account,
+			want:   "m/49'/4'/7'",
+		},
+		{
+			name:   "testnet3 bip84",
+			params: &rddnet.TestNet3Params,
+			path:   rddnet.TestNet3Params.BIP84Account,
+			want:   "m/84'/1'/7'",
+		},
+		{
+			name:   "simnet bip44",
+			params: &rddnet.SimNetParams,
+			path:   rddnet.SimNetParams.BIP44Account,
+			want:   "m/44'/115'/7'",
+		},
+	}
+
+	for _, test := range tests {
+		account := test.path(7)
+		if got := account.String(); got != test.want {
+			t.Errorf("%s: mismatched account path: got %s, want %s",
+				test.name, got, test.want)
+			continue
+		}
+
+		addr := account.Child(1).Child(5)
+		if got, want := addr.String(), test.want+"/1/5"; got != want {
+			t.Errorf("%s: mismatched address path: got %s, want %s",
+				test.name, got, want)
+		}
+		if len(account) != 3 {
+			t.Errorf("%s: Child modified the account path", test.name)
+		}
+		if err := test.params.CheckDerivationPath(addr); err != nil {
+			t.Errorf("%s: unexpected error checking path: %v",
+				test.name, err)
+		}
+		if _, err := test.params.ParseDerivationPath(addr.String()); err != nil {
+			t.Errorf("%s: unexpected error parsing path: %v",
+				test.name, err)
+		}
+	}
+
+	// Mainnet paths must be rejected by the test networks and vice versa.
+	mainPath := rddnet.MainNetParams.BIP44Account(0).String()
+	_, err := rddnet.TestNet3Params.ParseDerivationPath(mainPath)
+	if err != rddnet.ErrHDCoinTypeMismatch {
+		t.Errorf("testnet3 parse of %s: got %v, want %v", mainPath, err,
+			rddnet.ErrHDCoinTypeMismatch)
+	}
+	testPath := rddnet.TestNet3Params.BIP84Account(0).Child(0)
+	err = rddnet.MainNetParams.CheckDerivationPath(testPath)
+	if err != rddnet.ErrHDCoinTypeMismatch {
+		t.Errorf("mainnet check of %v: got %v, want %v", testPath, err,
+			rddnet.ErrHDCoinTypeMismatch)
+	}
+
+	// An unhardened coin type does not match either.
+	unhardened := rddnet.DerivationPath{rddnet.Hardened(44), 4}
+	err = rddnet.MainNetParams.CheckDerivationPath(unhardened)
+	if err != rddnet.ErrHDCoinTypeMismatch {
+		t.Errorf("mainnet check of %v: got %v, want %v", unhardened, err,
+			rddnet.ErrHDCoinTypeMismatch)
+	}
+
+	// Paths with other purposes are not checked.
+	other := rddnet.DerivationPath{rddnet.Hardened(0), 1}
+	if err := rddnet.MainNetParams.CheckDerivationPath(other); err != nil {
+		t.Errorf("mainnet check of %v: unexpected error %v", other, err)
+	}
+}
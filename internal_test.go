@@ -1,7 +1,12 @@
 package rddnet
 
 import (
+	"math/big"
+	"reflect"
 	"testing"
+
+	"github.com/reddcoin-project/rddnet/merkle"
+	"github.com/reddcoin-project/rddwire"
 )
 
 func TestInvalidShaStr(t *testing.T) {
@@ -12,3 +17,159 @@ func TestInvalidShaStr(t *testing.T) {
 	}()
 	newShaHashFromStr("banana")
 }
+
+// TestGenesisMerkleRoots ensures every hard-coded genesis merkle root is the
+// merkle root of the transactions it is documented to be the root of.
+func TestGenesisMerkleRoots(t *testing.T) {
+	tests := []struct {
+		name   string
+		root   rddwire.ShaHash
+		txs    []*rddwire.MsgTx
+		params *Params
+	}{
+		{"genesisMerkleRoot", genesisMerkleRoot,
+			[]*rddwire.MsgTx{&genesisCoinbaseTx}, &MainNetParams},
+		{"genesisMerkleRootBtc", genesisMerkleRootBtc,
+			[]*rddwire.MsgTx{&genesisCoinbaseTxBtc}, &TestNet3Params},
+		{"regTestGenesisMerkleRoot", regTestGenesisMerkleRoot,
+			regTestGenesisBlock.Transactions, &RegressionNetParams},
+		{"testNet3GenesisMerkleRoot", testNet3GenesisMerkleRoot,
+			testNet3GenesisBlock.Transactions, &TestNet3Params},
+		{"simNetGenesisMerkleRoot", simNetGenesisMerkleRoot,
+			simNetGenesisBlock.Transactions, &SimNetParams},
+		{"genesisBlock", genesisBlock.Header.MerkleRoot,
+			genesisBlock.Transactions, &MainNetParams},
+	}
+
+	for _, test := range tests {
+		root := merkle.TxRoot(test.txs, test.params.TxSha)
+		if root != test.root {
+			t.Errorf("%s: got %v want %v", test.name, test.root, root)
+		}
+	}
+}
+
+// unregister removes the passed network from the registry of this package.
+// Lookup values it shares with the remaining networks stay registered.
+func unregister(params *Params) {
+	var remaining []*Params
+	for _, other := range registeredParams {
+		if other != params {
+			remaining = append(remaining, other)
+		}
+	}
+	registeredParams = remaining
+	delete(registeredNets, params.Net)
+
+	delete(pubKeyHashAddrIDs, params.PubKeyHashAddrID)
+	delete(scriptHashAddrIDs, params.ScriptHashAddrID)
+	delete(hdPrivToPubKeyIDs, params.HDPrivateKeyID)
+	for _, other := range registeredParams {
+		pubKeyHashAddrIDs[other.PubKeyHashAddrID] = struct{}{}
+		scriptHashAddrIDs[other.ScriptHashAddrID] = struct{}{}
+		if other.HDPrivateKeyID == params.HDPrivateKeyID {
+			hdPrivToPubKeyIDs[other.HDPrivateKeyID] =
+				other.HDPublicKeyID[:]
+		}
+	}
+	for hrp, other := range bech32SegwitPrefixes {
+		if other == params {
+			delete(bech32SegwitPrefixes, hrp)
+		}
+	}
+	for name, other := range netNames {
+		if other == params {
+			delete(netNames, name)
+		}
+	}
+}
+
+// TstRegister registers the passed network like Register for the duration of
+// the test.  The network is unregistered when the test and all of its subtests
+// complete, so mock networks registered by one test are never visible to
+// another.  The error returned by Register is returned, and nothing needs to be
+// unregistered when it is not nil.
+func TstRegister(t *testing.T, params *Params) error {
+	if err := Register(params); err != nil {
+		return err
+	}
+	t.Cleanup(func() { unregister(params) })
+	return nil
+}
+
+// registry returns copies of the registry of this package.
+func registry() []interface{} {
+	copyMap := func(m interface{}) interface{} {
+		v := reflect.ValueOf(m)
+		c := reflect.MakeMap(v.Type())
+		for _, key := range v.MapKeys() {
+			c.SetMapIndex(key, v.MapIndex(key))
+		}
+		return c.Interface()
+	}
+	return []interface{}{
+		append([]*Params{}, registeredParams...),
+		copyMap(registeredNets),
+		copyMap(pubKeyHashAddrIDs),
+		copyMap(scriptHashAddrIDs),
+		copyMap(hdPrivToPubKeyIDs),
+		copyMap(bech32SegwitPrefixes),
+		copyMap(netNames),
+	}
+}
+
+// TestUnregister ensures unregistering a network restores the registry to the
+// state it was in before the network was registered, including lookup values
+// the network shares with the default networks.
+func TestUnregister(t *testing.T) {
+	before := registry()
+
+	params := &Params{
+		Name:             "unregisternet",
+		Aliases:          []string{"unregister"},
+		Net:              0xffffffb1,
+		PubKeyHashAddrID: MainNetParams.PubKeyHashAddrID,
+		ScriptHashAddrID: 0xb1,
+		HDPrivateKeyID:   [4]byte{0xb1, 0xb1, 0xb1, 0xb1},
+		HDPublicKeyID:    [4]byte{0xb2, 0xb2, 0xb2, 0xb2},
+		Bech32HRPSegwit:  "URN",
+	}
+	params.Policy = MainNetParams.Policy
+	params.RelayNonStdTxs = params.Policy.RelayNonStdTxs
+	if err := Register(params); err != nil {
+		t.Fatalf("Register: unexpected error %v", err)
+	}
+	unregister(params)
+
+	if after := registry(); !reflect.DeepEqual(after, before) {
+		t.Errorf("registry not restored by unregister")
+	}
+	if !IsPubKeyHashAddrID(MainNetParams.PubKeyHashAddrID) {
+		t.Errorf("shared address magic unregistered")
+	}
+	if err := Register(params); err != nil {
+		t.Errorf("Register after unregister: unexpected error %v", err)
+	}
+	unregister(params)
+}
+
+// TestSolveDevNetHeader ensures the nonce search for development network
+// genesis blocks stops once every nonce is tried instead of looping forever.
+func TestSolveDevNetHeader(t *testing.T) {
+	header := SimNetParams.GenesisBlock.Header
+	err := solveDevNetHeader(&header, big.NewInt(0), 1000)
+	if err != ErrDevNetNoNonce {
+		t.Errorf("unsolvable target: got error %v want %v", err,
+			ErrDevNetNoNonce)
+	}
+	if header.Nonce != 1000 {
+		t.Errorf("unsolvable target: stopped at nonce %d want 1000",
+			header.Nonce)
+	}
+
+	err = solveDevNetHeader(&header, CompactToBig(devNetPowLimitBits),
+		1000)
+	if err != nil {
+		t.Errorf("solvable target: unexpected error %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet
+
+// BlockLimits defines the consensus limits on the size and cost of blocks
+// starting at a block height.  Networks which changed their limits define one
+// BlockLimits for each change.
+type BlockLimits struct {
+	// Height is the height of the first block the limits apply to.
+	Height int64
+
+	// MaxSize is the maximum serialized size of a block in bytes.
+	MaxSize uint32
+
+	// MaxSigOps is the maximum number of signature operations in a block.
+	MaxSigOps uint32
+
+	// MaxWeight is the maximum weight of a block as defined in BIP0141.
+	// Zero means block weight is not limited, as is the case before
+	// segwit activates.
+	MaxWeight uint32
+}
+
+// These constants define the block limits of the default networks.
+const (
+	// DefaultMaxBlockSize is the maximum serialized size of a block in
+	// bytes.
+	DefaultMaxBlockSize = 1000000
+
+	// DefaultMaxBlockSigOps is the maximum number of signature operations
+	// in a block.
+	DefaultMaxBlockSigOps = DefaultMaxBlockSize / 50
+)
+
+// BlockLimitsAt returns the block limits which apply to the block at the
+// passed height.  The zero value, which does not limit blocks at all, is
+// returned when the network defines no limits for the height.
+func (p *Params) BlockLimitsAt(height int64) BlockLimits {
+	var limits BlockLimits
+	for _, l := range p.BlockLimits {
+		if l.Height > height {
+			break
+		}
+		limits = l
+	}
+	return limits
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet_test
+
+import (
+	"testing"
+
+	"github.com/reddcoin-project/rddnet"
+)
+
+// TestBlockLimitsAt ensures the block limits which apply at a height are the
+// most recently activated ones.
+func TestBlockLimitsAt(t *testing.T) {
+	legacy := rddnet.BlockLimits{
+		Height:    0,
+		MaxSize:   rddnet.DefaultMaxBlockSize,
+		MaxSigOps: rddnet.DefaultMaxBlockSigOps,
+	}
+	segwit := rddnet.BlockLimits{
+		Height:    1000,
+		MaxSize:   4000000,
+		MaxSigOps: 80000,
+		MaxWeight: 4000000,
+	}
+	tuned := rddnet.Params{
+		BlockLimits: []rddnet.BlockLimits{legacy, segwit},
+	}
+
+	tests := []struct {
+		name   string
+		params *rddnet.Params
+		height int64
+		want   rddnet.BlockLimits
+	}{
+		{"mainnet genesis", &rddnet.MainNetParams, 0, legacy},
+		{"mainnet later", &rddnet.MainNetParams, 1000000, legacy},
+		{"tuned genesis", &tuned, 0, legacy},
+		{"tuned before activation", &tuned, 999, legacy},
+		{"tuned activation", &tuned, 1000, segwit},
+		{"tuned after activation", &tuned, 1001, segwit},
+		{"no limits", &rddnet.Params{}, 0, rddnet.BlockLimits{}},
+		{"negative height", &tuned, -1, rddnet.BlockLimits{}},
+	}
+
+	for _, test := range tests {
+		got := test.params.BlockLimitsAt(test.height)
+		if got != test.want {
+			t.Errorf("%s: BlockLimitsAt(%d): got %+v want %+v",
+				test.name, test.height, got, test.want)
+		}
+	}
+
+	// Every default network limits blocks from the genesis block on, so
+	// crossing from before the genesis block to it activates the limits,
+	// and they stay in force after the last checkpoint.
+	for _, params := range defaultNets {
+		if got := params.BlockLimitsAt(-1); got != (rddnet.BlockLimits{}) {
+			t.Errorf("%s: unexpected block limits %+v before the "+
+				"genesis block", params.Name, got)
+		}
+		if got := params.BlockLimitsAt(0); got != legacy {
+			t.Errorf("%s: unexpected genesis block limits %+v",
+				params.Name, got)
+		}
+		checkpoints := params.Checkpoints
+		if len(checkpoints) > 0 {
+			height := checkpoints[len(checkpoints)-1].Height + 1
+			if got := params.BlockLimitsAt(height); got != legacy {
+				t.Errorf("%s: unexpected block limits %+v after "+
+					"the last checkpoint", params.Name, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/reddcoin-project/rddwire"
+)
+
+// netMagicSize is the number of bytes of the network magic which starts every
+// message header.
+const netMagicSize = 4
+
+// ErrMessageHeaderTooShort describes an error where a message header does not
+// hold enough bytes to read the network magic from.
+var ErrMessageHeaderTooShort = errors.New("message header too short to " +
+	"contain the network magic")
+
+// UnknownNetError describes an error where a message header starts with a
+// network magic which is not used by any default or registered network.
+type UnknownNetError struct {
+	Net rddwire.ReddcoinNet
+}
+
+// Error satisfies the error interface and prints human-readable errors.
+func (e UnknownNetError) Error() string {
+	return fmt.Sprintf("unknown Reddcoin network magic 0x%08x", uint32(e.Net))
+}
+
+// ParamsForNet returns the parameters of the default or registered network
+// identified by the passed network magic.  When the network is not known, an
+// UnknownNetError will be returned.
+func ParamsForNet(net rddwire.ReddcoinNet) (*Params, error) {
+	params, ok := registeredNets[net]
+	if !ok {
+		return nil, UnknownNetError{Net: net}
+	}
+	return params, nil
+}
+
+// NetworkForMessageHeader reads the network magic from the start of the passed
+// raw message header and returns the parameters of the default or registered
+// network it belongs to.  Only the magic is examined, so it is up to the
+// caller to validate the remaining header fields.  ErrMessageHeaderTooShort is
+// returned when the header is shorter than the magic and an UnknownNetError
+// when the magic is not known.
+func NetworkForMessageHeader(header []byte) (*Params, error) {
+	if len(header) < netMagicSize {
+		return nil, ErrMessageHeaderTooShort
+	}
+	net := rddwire.ReddcoinNet(binary.LittleEndian.Uint32(header))
+	return ParamsForNet(net)
+}
+
+// IndexNetworkMagic returns the offset of the first default or registered
+// network magic in b along with the parameters of its network.  An offset of
+// -1 and nil parameters are returned when b does not contain a known magic.
+func IndexNetworkMagic(b []byte) (int, *Params) {
+	for i := 0; i+netMagicSize <= len(b); i++ {
+		params, err := NetworkForMessageHeader(b[i:])
+		if err == nil {
+			return i, params
+		}
+	}
+	return -1, nil
+}
+
+// SkipToNetworkMagic discards bytes from the passed reader until the next
+// unread bytes are the magic of a default or registered network.  The magic
+// itself is left unread so the full message header may be read afterwards.
+// The number of discarded bytes is returned along with the parameters of the
+// network.  This allows a reader which lost track of message boundaries to
+// resynchronize.  Since the magic bytes may also occur within a message
+// payload, callers should validate the header and its checksum after
+// resynchronizing and skip a byte and try again should that fail.
+//
+// Any error from the underlying reader, including io.EOF when the stream ends
+// before a magic is found, is returned along with the number of bytes that
+// were discarded.
+func SkipToNetworkMagic(r *bufio.Reader) (*Params, int, error) {
+	var discarded int
+	for {
+		magic, err := r.Peek(netMagicSize)
+		if err != nil {
+			// Drop any trailing bytes which can not start a magic
+			// so the reader is left at the end of the stream.
+			n, _ := r.Discard(len(magic))
+			return nil, discarded + n, err
+		}
+		if params, err := NetworkForMessageHeader(magic); err == nil {
+			return params, discarded, nil
+		}
+		if _, err := r.Discard(1); err != nil {
+			return nil, discarded, err
+		}
+		discarded++
+	}
+}
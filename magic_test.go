@@ -0,0 +1,126 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/reddcoin-project/rddnet"
+	"github.com/reddcoin-project/rddwire"
+)
+
+// magicBytes returns the wire encoding of the passed network magic.
+func magicBytes(net rddwire.ReddcoinNet) []byte {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], uint32(net))
+	return b[:]
+}
+
+// TestNetworkForMessageHeader ensures the network of a message header is
+// determined from its magic.
+func TestNetworkForMessageHeader(t *testing.T) {
+	// verack message header without the magic.
+	verack := []byte{
+		0x76, 0x65, 0x72, 0x61, 0x63, 0x6b, 0x00, 0x00, // verack
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x5d, 0xf6, 0xe0, 0xe2,
+	}
+
+	tests := []struct {
+		name   string
+		header []byte
+		want   *rddnet.Params
+		err    error
+	}{
+		{
+			name:   "mainnet",
+			header: append(magicBytes(rddwire.MainNet), verack...),
+			want:   &rddnet.MainNetParams,
+		},
+		{
+			name:   "regtest",
+			header: append(magicBytes(rddwire.TestNet), verack...),
+			want:   &rddnet.RegressionNetParams,
+		},
+		{
+			name:   "testnet3",
+			header: append(magicBytes(rddwire.TestNet3), verack...),
+			want:   &rddnet.TestNet3Params,
+		},
+		{
+			name:   "simnet magic only",
+			header: magicBytes(rddwire.SimNet),
+			want:   &rddnet.SimNetParams,
+		},
+		{
+			name:   "unknown",
+			header: append(magicBytes(0x01020304), verack...),
+			err:    rddnet.UnknownNetError{Net: 0x01020304},
+		},
+		{
+			name:   "short",
+			header: magicBytes(rddwire.MainNet)[:3],
+			err:    rddnet.ErrMessageHeaderTooShort,
+		},
+	}
+
+	for _, test := range tests {
+		params, err := rddnet.NetworkForMessageHeader(test.header)
+		if err != test.err {
+			t.Errorf("%s: unexpected error: got %v, want %v", test.name,
+				err, test.err)
+			continue
+		}
+		if params != test.want {
+			t.Errorf("%s: mismatched network: got %v, want %v",
+				test.name, params, test.want)
+		}
+	}
+}
+
+// TestSkipToNetworkMagic ensures a stream is resynchronized at the next known
+// network magic.
+func TestSkipToNetworkMagic(t *testing.T) {
+	garbage := []byte{0x00, 0xfb, 0xc0, 0xb6, 0x01, 0xff}
+	stream := append(append([]byte{}, garbage...),
+		magicBytes(rddwire.TestNet3)...)
+	stream = append(stream, 0xaa, 0xbb)
+
+	offset, params := rddnet.IndexNetworkMagic(stream)
+	if offset != len(garbage) || params != &rddnet.TestNet3Params {
+		t.Errorf("IndexNetworkMagic: got %d, %v want %d, %v", offset,
+			params, len(garbage), &rddnet.TestNet3Params)
+	}
+
+	r := bufio.NewReader(bytes.NewReader(stream))
+	params, n, err := rddnet.SkipToNetworkMagic(r)
+	if err != nil || n != len(garbage) || params != &rddnet.TestNet3Params {
+		t.Fatalf("SkipToNetworkMagic: got %v, %d, %v want %v, %d, nil",
+			params, n, err, &rddnet.TestNet3Params, len(garbage))
+	}
+
+	// The magic must be left unread.
+	rest, _ := io.ReadAll(r)
+	if want := stream[len(garbage):]; !bytes.Equal(rest, want) {
+		t.Errorf("SkipToNetworkMagic left %x unread, want %x", rest, want)
+	}
+
+	// A stream without a known magic is consumed entirely.
+	offset, params = rddnet.IndexNetworkMagic(garbage)
+	if offset != -1 || params != nil {
+		t.Errorf("IndexNetworkMagic: got %d, %v want -1, nil", offset,
+			params)
+	}
+	r = bufio.NewReader(bytes.NewReader(garbage))
+	params, n, err = rddnet.SkipToNetworkMagic(r)
+	if err != io.EOF || n != len(garbage) || params != nil {
+		t.Errorf("SkipToNetworkMagic: got %v, %d, %v want nil, %d, %v",
+			params, n, err, len(garbage), io.EOF)
+	}
+}
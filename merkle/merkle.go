@@ -0,0 +1,153 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package merkle computes the merkle roots of the transactions of Reddcoin blocks
+and the merkle branches which prove a transaction is included in a block.
+
+The merkle tree is built from the double SHA256 hashes of the transactions.
+Each level of the tree is built by hashing the concatenation of every pair of
+hashes of the level below it.  A level with an odd number of hashes is padded
+by duplicating its last hash.
+
+The duplication makes the tree malleable, as described in CVE-2012-2459: the
+transactions of a block may be extended with a copy of a suffix of them
+without changing the merkle root.  Use RootMutated to detect such lists of
+transactions.
+*/
+package merkle
+
+import (
+	"crypto/sha256"
+	"errors"
+
+	"github.com/reddcoin-project/rddwire"
+)
+
+// ErrIndexOutOfRange describes an error where a merkle branch is requested for
+// a leaf which is not in the tree.
+var ErrIndexOutOfRange = errors.New("merkle leaf index out of range")
+
+// HashMerkleBranches returns the double SHA256 hash of the concatenation of
+// the passed left and right hashes.  It is used to create the parent of two
+// nodes of a merkle tree.
+func HashMerkleBranches(left, right *rddwire.ShaHash) rddwire.ShaHash {
+	var buf [rddwire.HashSize * 2]byte
+	copy(buf[:rddwire.HashSize], left[:])
+	copy(buf[rddwire.HashSize:], right[:])
+	first := sha256.Sum256(buf[:])
+	return rddwire.ShaHash(sha256.Sum256(first[:]))
+}
+
+// nextLevel returns the parent level of the passed level of a merkle tree,
+// which must have at least two hashes, and whether any pair of hashes hashed
+// into the parent level are identical.
+func nextLevel(level []rddwire.ShaHash) ([]rddwire.ShaHash, bool) {
+	mutated := false
+	next := make([]rddwire.ShaHash, 0, (len(level)+1)/2)
+	for i := 0; i < len(level); i += 2 {
+		right := &level[len(level)-1]
+		if i+1 < len(level) {
+			right = &level[i+1]
+			if level[i] == *right {
+				mutated = true
+			}
+		}
+		next = append(next, HashMerkleBranches(&level[i], right))
+	}
+	return next, mutated
+}
+
+// RootMutated returns the merkle root of the passed leaf hashes and whether
+// the leaves are mutated.  The leaves are mutated when any level of the tree
+// hashes two identical hashes which were not created by padding an odd level,
+// which is the case for every list of leaves extended as described in
+// CVE-2012-2459.  Blocks with mutated transactions must be rejected without
+// marking their merkle root invalid since the unmutated transactions may be
+// valid.  The root of no leaves is the zero hash.
+func RootMutated(leaves []rddwire.ShaHash) (rddwire.ShaHash, bool) {
+	if len(leaves) == 0 {
+		return rddwire.ShaHash{}, false
+	}
+	mutated := false
+	level := leaves
+	for len(level) > 1 {
+		var levelMutated bool
+		level, levelMutated = nextLevel(level)
+		mutated = mutated || levelMutated
+	}
+	return level[0], mutated
+}
+
+// Root returns the merkle root of the passed leaf hashes.  The root of no
+// leaves is the zero hash.
+func Root(leaves []rddwire.ShaHash) rddwire.ShaHash {
+	root, _ := RootMutated(leaves)
+	return root
+}
+
+// TxRoot returns the merkle root of the passed transactions, such as the
+// transactions of a block, hashed with the passed function.  The hash of a
+// transaction depends on whether its format serializes a timestamp, so the
+// function should be the TxSha method of the parameters of the network the
+// transactions belong to rather than the TxSha method of the transactions,
+// which follows the version rules of rddwire.
+func TxRoot(txs []*rddwire.MsgTx, txHash func(*rddwire.MsgTx) rddwire.ShaHash) rddwire.ShaHash {
+	leaves := make([]rddwire.ShaHash, 0, len(txs))
+	for _, tx := range txs {
+		leaves = append(leaves, txHash(tx))
+	}
+	return Root(leaves)
+}
+
+// Branch returns the merkle branch which proves the leaf at the passed index is
+// part of the tree of the passed leaf hashes.  The branch holds the sibling of
+// the leaf followed by the sibling of each of its ancestors below the root.
+// ErrIndexOutOfRange is returned when there is no leaf at the index.
+func Branch(leaves []rddwire.ShaHash, index int) ([]rddwire.ShaHash, error) {
+	if index < 0 || index >= len(leaves) {
+		return nil, ErrIndexOutOfRange
+	}
+	var branch []rddwire.ShaHash
+	level := leaves
+	for len(level) > 1 {
+		sibling := index ^ 1
+		if sibling >= len(level) {
+			sibling = index
+		}
+		branch = append(branch, level[sibling])
+		level, _ = nextLevel(level)
+		index /= 2
+	}
+	return branch, nil
+}
+
+// BranchRoot returns the merkle root proven by the passed branch for the passed
+// leaf at the passed index.
+func BranchRoot(leaf rddwire.ShaHash, branch []rddwire.ShaHash,
+	index int) rddwire.ShaHash {
+
+	hash := leaf
+	for _, sibling := range branch {
+		if index&1 == 0 {
+			hash = HashMerkleBranches(&hash, &sibling)
+		} else {
+			hash = HashMerkleBranches(&sibling, &hash)
+		}
+		index >>= 1
+	}
+	return hash
+}
+
+// VerifyBranch returns whether the passed branch proves the passed leaf at the
+// passed index is part of the tree with the passed root.
+func VerifyBranch(leaf rddwire.ShaHash, branch []rddwire.ShaHash, index int,
+	root rddwire.ShaHash) bool {
+
+	// Each index may only be proven by a branch of the height of its tree.
+	if index < 0 || index>>uint(len(branch)) != 0 {
+		return false
+	}
+	return BranchRoot(leaf, branch, index) == root
+}
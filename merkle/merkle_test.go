@@ -0,0 +1,181 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package merkle_test
+
+import (
+	"testing"
+
+	"github.com/reddcoin-project/rddnet/merkle"
+	"github.com/reddcoin-project/rddwire"
+)
+
+// hashFromStr returns the hash for the passed byte-reversed hex string and
+// panics on error.  It is only used with hard-coded hashes.
+func hashFromStr(s string) rddwire.ShaHash {
+	hash, err := rddwire.NewShaHashFromStr(s)
+	if err != nil {
+		panic(err)
+	}
+	return *hash
+}
+
+// block100000Hashes are the transaction hashes of block 100000 of the bitcoin
+// main network, which has a well known merkle root.
+var block100000Hashes = []rddwire.ShaHash{
+	hashFromStr("8c14f0db3df150123e6f3dbbf30f8b955a8249b62ac1d1ff16284aefa3d06d87"),
+	hashFromStr("fff2525b8931402dd09222c50775608f75787bd2b87e56995a7bdd30f79702c4"),
+	hashFromStr("6359f0868171b1d194cbee1af2f16ea598ae8fad666d9b012c8ed2b79a236ec4"),
+	hashFromStr("e9a66845e05d5abc0ad04ec80f774a7e585c6e8db975962d069a522137b80c1d"),
+}
+
+// block100000Root is the merkle root of block 100000 of the bitcoin main
+// network.
+var block100000Root = hashFromStr("f3e94742aca4b5ef85488dc37c06c3282295ffec960994b2c0d5ac2a25a95766")
+
+// leaves returns n distinct leaf hashes.
+func leaves(n int) []rddwire.ShaHash {
+	hashes := make([]rddwire.ShaHash, n)
+	for i := range hashes {
+		hashes[i][0] = byte(i + 1)
+	}
+	return hashes
+}
+
+// TestRoot ensures merkle roots are calculated correctly, including for
+// trees with levels of an odd number of hashes.
+func TestRoot(t *testing.T) {
+	if root := merkle.Root(block100000Hashes); root != block100000Root {
+		t.Errorf("Root: got %v want %v", root, block100000Root)
+	}
+
+	if root := merkle.Root(nil); root != (rddwire.ShaHash{}) {
+		t.Errorf("Root of no leaves: got %v want zero hash", root)
+	}
+
+	single := leaves(1)
+	if root := merkle.Root(single); root != single[0] {
+		t.Errorf("Root of one leaf: got %v want %v", root, single[0])
+	}
+
+	// An odd level duplicates its last hash.
+	three := block100000Hashes[:3]
+	left := merkle.HashMerkleBranches(&three[0], &three[1])
+	right := merkle.HashMerkleBranches(&three[2], &three[2])
+	want := merkle.HashMerkleBranches(&left, &right)
+	if root := merkle.Root(three); root != want {
+		t.Errorf("Root of three leaves: got %v want %v", root, want)
+	}
+}
+
+// TestTxRoot ensures transactions are hashed with the passed function rather
+// than with their own TxSha method.
+func TestTxRoot(t *testing.T) {
+	txs := make([]*rddwire.MsgTx, len(block100000Hashes))
+	hashes := make(map[*rddwire.MsgTx]rddwire.ShaHash)
+	for i := range txs {
+		txs[i] = rddwire.NewMsgTx()
+		hashes[txs[i]] = block100000Hashes[i]
+	}
+	txHash := func(tx *rddwire.MsgTx) rddwire.ShaHash {
+		return hashes[tx]
+	}
+	if root := merkle.TxRoot(txs, txHash); root != block100000Root {
+		t.Errorf("TxRoot: got %v want %v", root, block100000Root)
+	}
+}
+
+// TestRootMutated ensures lists of leaves extended as described in
+// CVE-2012-2459 are detected without changing the merkle root.
+func TestRootMutated(t *testing.T) {
+	tests := []struct {
+		name    string
+		leaves  []rddwire.ShaHash
+		mutated bool
+	}{
+		{"no leaves", nil, false},
+		{"one leaf", leaves(1), false},
+		{"odd", leaves(3), false},
+		{"odd levels", leaves(5), false},
+		{"last duplicated", append(leaves(3), leaves(3)[2]), true},
+		{"pair duplicated", append(leaves(6), leaves(6)[4:]...), true},
+		{"first pair equal", []rddwire.ShaHash{{1}, {1}}, true},
+	}
+
+	for _, test := range tests {
+		root, mutated := merkle.RootMutated(test.leaves)
+		if mutated != test.mutated {
+			t.Errorf("%s: RootMutated: got mutated %v want %v",
+				test.name, mutated, test.mutated)
+		}
+		if root != merkle.Root(test.leaves) {
+			t.Errorf("%s: RootMutated and Root disagree", test.name)
+		}
+	}
+
+	// The mutations do not change the root.
+	if merkle.Root(leaves(3)) != merkle.Root(append(leaves(3), leaves(3)[2])) {
+		t.Error("duplicating the last odd leaf changed the root")
+	}
+	if merkle.Root(leaves(6)) != merkle.Root(append(leaves(6), leaves(6)[4:]...)) {
+		t.Error("duplicating the last odd pair changed the root")
+	}
+}
+
+// TestBranch ensures merkle branches prove every leaf of trees of various
+// sizes and nothing else.
+func TestBranch(t *testing.T) {
+	for n := 1; n <= 17; n++ {
+		hashes := leaves(n)
+		root := merkle.Root(hashes)
+		for i := range hashes {
+			branch, err := merkle.Branch(hashes, i)
+			if err != nil {
+				t.Errorf("Branch(%d leaves, %d): unexpected "+
+					"error %v", n, i, err)
+				continue
+			}
+			if !merkle.VerifyBranch(hashes[i], branch, i, root) {
+				t.Errorf("VerifyBranch(%d leaves, %d): branch "+
+					"does not verify", n, i)
+			}
+
+			// The branch must not prove another leaf or the leaf
+			// at another index.
+			other := (i + 1) % n
+			if other != i && merkle.VerifyBranch(hashes[other],
+				branch, i, root) {
+
+				t.Errorf("VerifyBranch(%d leaves, %d): branch "+
+					"proves leaf %d", n, i, other)
+			}
+			if merkle.VerifyBranch(hashes[i], branch,
+				i+1<<uint(len(branch)), root) {
+
+				t.Errorf("VerifyBranch(%d leaves, %d): branch "+
+					"verifies out of range index", n, i)
+			}
+		}
+
+		for _, index := range []int{-1, n} {
+			_, err := merkle.Branch(hashes, index)
+			if err != merkle.ErrIndexOutOfRange {
+				t.Errorf("Branch(%d leaves, %d): got error %v "+
+					"want %v", n, index, err,
+					merkle.ErrIndexOutOfRange)
+			}
+		}
+	}
+
+	branch, err := merkle.Branch(block100000Hashes, 2)
+	if err != nil {
+		t.Fatalf("Branch: unexpected error %v", err)
+	}
+	if got := merkle.BranchRoot(block100000Hashes[2], branch, 2); got != block100000Root {
+		t.Errorf("BranchRoot: got %v want %v", got, block100000Root)
+	}
+	if merkle.VerifyBranch(block100000Hashes[2], branch, -1, block100000Root) {
+		t.Error("VerifyBranch: negative index verifies")
+	}
+}
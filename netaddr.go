@@ -0,0 +1,263 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// onionSuffix is the top-level domain of Tor hidden services.
+const onionSuffix = ".onion"
+
+// These constants define the lengths of the base32 encoded names of Tor
+// hidden services, which are 16 characters for version 2 and 56 characters
+// for version 3 services.
+const (
+	onionV2Len = 16
+	onionV3Len = 56
+)
+
+var (
+	// ErrMissingHost describes an error where a network address does not
+	// hold a host.
+	ErrMissingHost = errors.New("network address has no host")
+
+	// ErrInvalidHost describes an error where the host of a network
+	// address is neither an IP address, a Tor hidden service, nor a valid
+	// host name.
+	ErrInvalidHost = errors.New("invalid host in network address")
+
+	// ErrInvalidPort describes an error where the port of a network
+	// address is not a decimal number in the range 1 to 65535.
+	ErrInvalidPort = errors.New("invalid port in network address")
+
+	// ErrNoDefaultPort describes an error where a network address has no
+	// port and the network has no default port to add to it.
+	ErrNoDefaultPort = errors.New("network address has no port and the " +
+		"network has no default port")
+)
+
+// ForeignPortError describes an error where the port of a network address is
+// the default port of another default or registered network, which usually
+// means the address was configured for the wrong network.
+type ForeignPortError struct {
+	// Port is the port of the address.
+	Port string
+
+	// Net is the name of the network which uses the port as its default
+	// port.
+	Net string
+}
+
+// Error satisfies the error interface and prints human-readable errors.
+func (e ForeignPortError) Error() string {
+	return fmt.Sprintf("port %s is the default port of the %s network",
+		e.Port, e.Net)
+}
+
+// normalizeConfig holds the settings applied by NormalizeOptions.
+type normalizeConfig struct {
+	allowForeignPorts bool
+}
+
+// NormalizeOption configures how NormalizeAddress normalizes addresses.
+type NormalizeOption func(*normalizeConfig)
+
+// AllowForeignPorts allows addresses with the default port of another default
+// or registered network, which NormalizeAddress rejects by default.
+func AllowForeignPorts() NormalizeOption {
+	return func(cfg *normalizeConfig) {
+		cfg.allowForeignPorts = true
+	}
+}
+
+// splitHostPort splits the passed address into its host and port.  Unlike
+// net.SplitHostPort, the port may be omitted, in which case an empty port is
+// returned, and IPv6 literals without a port need no brackets.
+func splitHostPort(hostport string) (host, port string, err error) {
+	switch {
+	case strings.HasPrefix(hostport, "["):
+		end := strings.IndexByte(hostport, ']')
+		if end < 0 {
+			return "", "", ErrInvalidHost
+		}
+		host, rest := hostport[1:end], hostport[end+1:]
+		switch {
+		case rest == "":
+		case strings.HasPrefix(rest, ":"):
+			port = rest[1:]
+			if port == "" {
+				return "", "", ErrInvalidPort
+			}
+		default:
+			return "", "", ErrInvalidHost
+		}
+		// Only IPv6 literals may be bracketed.
+		if !strings.Contains(host, ":") || parseIP(host) == nil {
+			return "", "", ErrInvalidHost
+		}
+		return host, port, nil
+
+	case strings.Count(hostport, ":") > 1:
+		// Unbracketed IPv6 literals can not have a port.
+		return hostport, "", nil
+
+	case strings.Contains(hostport, ":"):
+		i := strings.IndexByte(hostport, ':')
+		if hostport[i+1:] == "" {
+			return "", "", ErrInvalidPort
+		}
+		return hostport[:i], hostport[i+1:], nil
+	}
+	return hostport, "", nil
+}
+
+// parseIP parses the passed IP address, which may have an IPv6 zone.  Nil is
+// returned when it is not an IP address.
+func parseIP(host string) net.IP {
+	if i := strings.IndexByte(host, '%'); i >= 0 {
+		if i == len(host)-1 || !strings.Contains(host[:i], ":") {
+			return nil
+		}
+		host = host[:i]
+	}
+	return net.ParseIP(host)
+}
+
+// normalizeHost returns the canonical form of the passed host, which must be
+// an IP address, the name of a Tor hidden service, or a host name.  IP
+// addresses are printed in their shortest form, and names are lowercased with
+// the trailing dot of fully qualified names removed.
+func normalizeHost(host string) (string, error) {
+	if host == "" {
+		return "", ErrMissingHost
+	}
+	if ip := parseIP(host); ip != nil {
+		normalized := ip.String()
+		if i := strings.IndexByte(host, '%'); i >= 0 {
+			normalized += host[i:]
+		}
+		return normalized, nil
+	}
+
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if strings.HasSuffix(host, onionSuffix) {
+		name := strings.TrimSuffix(host, onionSuffix)
+		if len(name) != onionV2Len && len(name) != onionV3Len {
+			return "", ErrInvalidHost
+		}
+		for i := 0; i < len(name); i++ {
+			c := name[i]
+			if (c < 'a' || c > 'z') && (c < '2' || c > '7') {
+				return "", ErrInvalidHost
+			}
+		}
+		return host, nil
+	}
+
+	// Host names are made of dot separated labels of letters, digits, and
+	// hyphens as described in RFC 1123.  A name of only numeric labels
+	// would be a malformed IPv4 address.
+	if len(host) > 253 {
+		return "", ErrInvalidHost
+	}
+	numeric := true
+	for _, label := range strings.Split(host, ".") {
+		if len(label) == 0 || len(label) > 63 || label[0] == '-' ||
+			label[len(label)-1] == '-' {
+
+			return "", ErrInvalidHost
+		}
+		for i := 0; i < len(label); i++ {
+			c := label[i]
+			switch {
+			case c >= '0' && c <= '9':
+			case c >= 'a' && c <= 'z', c == '-':
+				numeric = false
+			default:
+				return "", ErrInvalidHost
+			}
+		}
+	}
+	if numeric {
+		return "", ErrInvalidHost
+	}
+	return host, nil
+}
+
+// normalizePort returns the canonical form of the passed port, which must be a
+// decimal number in the range 1 to 65535.
+func normalizePort(port string) (string, error) {
+	if port == "" || port[0] < '0' || port[0] > '9' {
+		return "", ErrInvalidPort
+	}
+	n, err := strconv.ParseUint(port, 10, 16)
+	if err != nil || n == 0 {
+		return "", ErrInvalidPort
+	}
+	return strconv.FormatUint(n, 10), nil
+}
+
+// isP2PPort returns whether the passed normalized port is the DefaultPort or
+// P2PPort of the network.
+func isP2PPort(p *Params, port string) bool {
+	return port == formatP2PPort(p) || port == formatPort(p.P2PPort)
+}
+
+// NormalizeAddress returns the passed network address of a node of the network
+// in the host:port form accepted by net.Dial.  The default port of the network
+// is added when the address has no port.  The host may be an IPv4 or IPv6
+// address, the name of a Tor hidden service, or a host name.  IPv6 addresses
+// may be passed with or without brackets, but are always returned with them.
+// Hosts are returned in their canonical form, so normalized addresses of the
+// same node compare equal.  Fully qualified host names such as
+// seed.example.com. are accepted and returned without the trailing dot.
+//
+// The default port is DefaultPort, or P2PPort for networks which only set the
+// typed port.  ErrMissingHost, ErrInvalidHost, or ErrInvalidPort is returned
+// when the address is malformed, and ErrNoDefaultPort is returned when the
+// address has no port and the network has no default port.  A
+// ForeignPortError is returned when the port is the DefaultPort or P2PPort of
+// another default or registered network, unless the option returned by
+// AllowForeignPorts is passed.
+func (p *Params) NormalizeAddress(hostport string, opts ...NormalizeOption) (string, error) {
+	var cfg normalizeConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	host, port, err := splitHostPort(hostport)
+	if err != nil {
+		return "", err
+	}
+	host, err = normalizeHost(host)
+	if err != nil {
+		return "", err
+	}
+	if port == "" {
+		port = formatP2PPort(p)
+		if port == "" {
+			return "", ErrNoDefaultPort
+		}
+	}
+	port, err = normalizePort(port)
+	if err != nil {
+		return "", err
+	}
+
+	if !cfg.allowForeignPorts && !isP2PPort(p, port) {
+		for _, other := range registeredParams {
+			if other.Net != p.Net && isP2PPort(other, port) {
+				return "", ForeignPortError{Port: port,
+					Net: other.Name}
+			}
+		}
+	}
+	return net.JoinHostPort(host, port), nil
+}
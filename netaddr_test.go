@@ -0,0 +1,137 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet_test
+
+import (
+	"testing"
+
+	"github.com/reddcoin-project/rddnet"
+)
+
+// TestNormalizeAddress ensures addresses are normalized with the default port
+// of the network and that malformed addresses and ports of other networks are
+// rejected.
+func TestNormalizeAddress(t *testing.T) {
+	onionV2 := "expyuzz4wqqyqhjn.onion"
+	onionV3 := "2gzyxa5ihm7nsggfxnu52rck2vv4rvmdlkiu3zzui5du4xyclen53wid.onion"
+
+	tests := []struct {
+		name    string
+		addr    string
+		want    string
+		wantErr error
+	}{
+		{"ipv4", "127.0.0.1", "127.0.0.1:45444", nil},
+		{"ipv4 with port", "127.0.0.1:8080", "127.0.0.1:8080", nil},
+		{"ipv4 with default port", "10.0.0.1:45444", "10.0.0.1:45444", nil},
+		{"ipv6", "::1", "[::1]:45444", nil},
+		{"bracketed ipv6", "[2001:DB8:0:0::1]", "[2001:db8::1]:45444", nil},
+		{"ipv6 with port", "[::1]:8080", "[::1]:8080", nil},
+		{"ipv6 with zone", "[fe80::1%eth0]:8080", "[fe80::1%eth0]:8080", nil},
+		{"ipv4-mapped ipv6", "[::ffff:10.0.0.1]", "10.0.0.1:45444", nil},
+		{"host name", "Seed.Reddcoin.com", "seed.reddcoin.com:45444", nil},
+		{"host name with port", "localhost:8080", "localhost:8080", nil},
+		{"leading zero port", "localhost:08080", "localhost:8080", nil},
+		{"onion v2", onionV2, onionV2 + ":45444", nil},
+		{"onion v3 with port", onionV3 + ":8080", onionV3 + ":8080", nil},
+		{"fqdn", "seed.example.com.", "seed.example.com:45444", nil},
+		{"fqdn with port", "Seed.Example.com.:8080",
+			"seed.example.com:8080", nil},
+		{"fqdn onion", onionV2 + ".", onionV2 + ":45444", nil},
+
+		{"empty", "", "", rddnet.ErrMissingHost},
+		{"port only", ":8080", "", rddnet.ErrMissingHost},
+		{"empty port", "localhost:", "", rddnet.ErrInvalidPort},
+		{"zero port", "localhost:0", "", rddnet.ErrInvalidPort},
+		{"port too large", "localhost:65536", "", rddnet.ErrInvalidPort},
+		{"signed port", "localhost:+80", "", rddnet.ErrInvalidPort},
+		{"named port", "localhost:http", "", rddnet.ErrInvalidPort},
+		{"unclosed bracket", "[::1:8080", "", rddnet.ErrInvalidHost},
+		{"bracketed ipv4", "[127.0.0.1]:8080", "", rddnet.ErrInvalidHost},
+		{"bracketed host name", "[localhost]", "", rddnet.ErrInvalidHost},
+		{"trailing data", "[::1]8080", "", rddnet.ErrInvalidHost},
+		{"bad ipv6", "::1::2", "", rddnet.ErrInvalidHost},
+		{"bad ipv4", "256.0.0.1", "", rddnet.ErrInvalidHost},
+		{"bad host name", "seed_1.example.com", "", rddnet.ErrInvalidHost},
+		{"empty label", "seed..example.com", "", rddnet.ErrInvalidHost},
+		{"hyphen label", "-seed.example.com", "", rddnet.ErrInvalidHost},
+		{"short onion", "expyuzz4wqqyqhj.onion", "", rddnet.ErrInvalidHost},
+		{"bad onion", "expyuzz4wqqyqhj1.onion", "", rddnet.ErrInvalidHost},
+		{"root", ".", "", rddnet.ErrInvalidHost},
+		{"two trailing dots", "seed.example.com..", "", rddnet.ErrInvalidHost},
+		{"ipv4 trailing dot", "127.0.0.1.", "", rddnet.ErrInvalidHost},
+	}
+	for _, test := range tests {
+		got, err := rddnet.MainNetParams.NormalizeAddress(test.addr)
+		if err != test.wantErr {
+			t.Errorf("%s: got error %v want %v", test.name, err,
+				test.wantErr)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%s: got %q want %q", test.name, got, test.want)
+		}
+	}
+
+	// The default ports of other networks are only allowed on request.
+	_, err := rddnet.MainNetParams.NormalizeAddress("127.0.0.1:18333")
+	want := rddnet.ForeignPortError{Port: "18333", Net: "testnet3"}
+	if err != want {
+		t.Errorf("foreign port: got error %v want %v", err, want)
+	}
+	got, err := rddnet.MainNetParams.NormalizeAddress("127.0.0.1:18333",
+		rddnet.AllowForeignPorts())
+	if err != nil || got != "127.0.0.1:18333" {
+		t.Errorf("allowed foreign port: got %q, %v", got, err)
+	}
+	got, err = rddnet.TestNet3Params.NormalizeAddress("[::1]")
+	if err != nil || got != "[::1]:18333" {
+		t.Errorf("testnet3: got %q, %v", got, err)
+	}
+
+	// Networks without a default port need addresses with a port.
+	noPort := rddnet.MainNetParams.Clone()
+	noPort.DefaultPort = ""
+	noPort.P2PPort = 0
+	if _, err := noPort.NormalizeAddress("127.0.0.1"); err != rddnet.ErrNoDefaultPort {
+		t.Errorf("no default port: got error %v want %v", err,
+			rddnet.ErrNoDefaultPort)
+	}
+	got, err = noPort.NormalizeAddress("127.0.0.1:8080")
+	if err != nil || got != "127.0.0.1:8080" {
+		t.Errorf("no default port with port: got %q, %v", got, err)
+	}
+
+	// Networks which only set the typed port fall back to it.
+	typedPort := rddnet.MainNetParams.Clone()
+	typedPort.DefaultPort = ""
+	got, err = typedPort.NormalizeAddress("127.0.0.1")
+	if err != nil || got != "127.0.0.1:45444" {
+		t.Errorf("typed port: got %q, %v", got, err)
+	}
+
+	// The typed ports of other networks are foreign as well.
+	typedNet := &rddnet.Params{
+		Name:             "typedportnet",
+		Net:              0xffffffb3,
+		P2PPort:          45555,
+		PubKeyHashAddrID: 0xb3,
+		ScriptHashAddrID: 0xb4,
+		Policy:           rddnet.MainNetParams.Policy,
+		RelayNonStdTxs:   rddnet.MainNetParams.RelayNonStdTxs,
+	}
+	if err := rddnet.TstRegister(t, typedNet); err != nil {
+		t.Fatalf("Register: unexpected error %v", err)
+	}
+	_, err = rddnet.MainNetParams.NormalizeAddress("127.0.0.1:45555")
+	want = rddnet.ForeignPortError{Port: "45555", Net: "typedportnet"}
+	if err != want {
+		t.Errorf("foreign typed port: got error %v want %v", err, want)
+	}
+	got, err = typedNet.NormalizeAddress("127.0.0.1")
+	if err != nil || got != "127.0.0.1:45555" {
+		t.Errorf("typed port network: got %q, %v", got, err)
+	}
+}
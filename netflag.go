@@ -0,0 +1,130 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// UnknownNetNameError describes an error where a network name does not name
+// any default or registered network.
+type UnknownNetNameError struct {
+	// Name is the name which could not be resolved.
+	Name string
+
+	// Valid holds the names of all default and registered networks at the
+	// time of the lookup, sorted alphabetically.
+	Valid []string
+}
+
+// Error satisfies the error interface and prints human-readable errors.
+func (e UnknownNetNameError) Error() string {
+	return fmt.Sprintf("unknown Reddcoin network %q (valid networks: %s)",
+		e.Name, strings.Join(e.Valid, ", "))
+}
+
+// registeredNames returns the sorted names of all default and registered
+// networks.
+func registeredNames() []string {
+	names := make([]string, 0, len(registeredParams))
+	for _, params := range registeredParams {
+		names = append(names, params.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParamsForName returns the parameters of the default or registered network
+// with the passed name or alias, such as testnet for testnet3.  Names are
+// compared case insensitively.  When no network has the name, an
+// UnknownNetNameError listing the valid names is returned.
+func ParamsForName(name string) (*Params, error) {
+	params, ok := netNames[strings.ToLower(name)]
+	if !ok {
+		return nil, UnknownNetNameError{Name: name,
+			Valid: registeredNames()}
+	}
+	return params, nil
+}
+
+// CanonicalName returns the Name of the default or registered network with
+// the passed name or alias.  For example, the canonical name of both main and
+// MainNet is mainnet.  When no network has the name, an UnknownNetNameError
+// listing the valid names is returned.
+func CanonicalName(alias string) (string, error) {
+	params, err := ParamsForName(alias)
+	if err != nil {
+		return "", err
+	}
+	return params.Name, nil
+}
+
+// NetworkFlag selects the active network by name.  It implements flag.Value
+// so it may be used as a command line flag, and encoding.TextUnmarshaler so it
+// may be used as a field of configuration structs decoded from text based
+// formats.  Names are resolved with ParamsForName, so networks registered
+// before the flag is parsed may be selected as well.
+//
+//	netFlag := rddnet.NewNetworkFlag(&rddnet.MainNetParams)
+//	flag.Var(netFlag, "net", "the Reddcoin network to operate on")
+type NetworkFlag struct {
+	// Params are the parameters of the selected network.
+	Params *Params
+}
+
+// NewNetworkFlag returns a NetworkFlag which selects the passed network until
+// it is set.
+func NewNetworkFlag(defaultParams *Params) *NetworkFlag {
+	return &NetworkFlag{Params: defaultParams}
+}
+
+// String returns the name of the selected network.  It satisfies the
+// flag.Value interface.
+func (f *NetworkFlag) String() string {
+	if f == nil || f.Params == nil {
+		return ""
+	}
+	return f.Params.Name
+}
+
+// Set selects the network with the passed name.  The selection is unchanged
+// when no network has the name.  It satisfies the flag.Value interface.
+func (f *NetworkFlag) Set(name string) error {
+	params, err := ParamsForName(name)
+	if err != nil {
+		return err
+	}
+	f.Params = params
+	return nil
+}
+
+// UnmarshalText selects the network with the passed name.  It satisfies the
+// encoding.TextUnmarshaler interface.
+func (f *NetworkFlag) UnmarshalText(text []byte) error {
+	return f.Set(string(text))
+}
+
+// MarshalText returns the name of the selected network.  It satisfies the
+// encoding.TextMarshaler interface.
+func (f NetworkFlag) MarshalText() ([]byte, error) {
+	return []byte(f.String()), nil
+}
+
+// SetFromEnv selects the network named by the passed environment variable.  The
+// selection is unchanged when the variable is unset or empty.  An error naming
+// the variable is returned when no network has the name.
+func (f *NetworkFlag) SetFromEnv(key string) error {
+	name := os.Getenv(key)
+	if name == "" {
+		return nil
+	}
+	if err := f.Set(name); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet_test
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/reddcoin-project/rddnet"
+)
+
+// TestParamsForName ensures networks are resolved by their names and aliases
+// and that unknown names produce an error listing the valid names.
+func TestParamsForName(t *testing.T) {
+	tests := []struct {
+		name string
+		want *rddnet.Params
+	}{
+		{"mainnet", &rddnet.MainNetParams},
+		{"MainNet", &rddnet.MainNetParams},
+		{"regtest", &rddnet.RegressionNetParams},
+		{"testnet3", &rddnet.TestNet3Params},
+		{"testnet", &rddnet.TestNet3Params},
+		{"simnet", &rddnet.SimNetParams},
+	}
+	for _, test := range tests {
+		params, err := rddnet.ParamsForName(test.name)
+		if err != nil || params != test.want {
+			t.Errorf("ParamsForName(%q): got %v, %v want %v", test.name,
+				params, err, test.want.Name)
+		}
+	}
+
+	_, err := rddnet.ParamsForName("bitcoin")
+	var nameErr rddnet.UnknownNetNameError
+	if !errors.As(err, &nameErr) {
+		t.Fatalf("ParamsForName(bitcoin): unexpected error %v", err)
+	}
+	for _, name := range []string{"mainnet", "regtest", "simnet",
+		"testnet3"} {
+
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("ParamsForName(bitcoin): error %q does not list %s",
+				err, name)
+		}
+	}
+}
+
+// TestCanonicalName ensures names and aliases resolve to the canonical names
+// of their networks.
+func TestCanonicalName(t *testing.T) {
+	tests := []struct {
+		alias string
+		want  string
+	}{
+		{"mainnet", "mainnet"},
+		{"Main", "mainnet"},
+		{"regression", "regtest"},
+		{"testnet", "testnet3"},
+		{"TEST", "testnet3"},
+		{"sim", "simnet"},
+	}
+	for _, test := range tests {
+		name, err := rddnet.CanonicalName(test.alias)
+		if err != nil || name != test.want {
+			t.Errorf("CanonicalName(%q): got %q, %v want %q",
+				test.alias, name, err, test.want)
+		}
+	}
+
+	_, err := rddnet.CanonicalName("bitcoin")
+	var unknown rddnet.UnknownNetNameError
+	if !errors.As(err, &unknown) || unknown.Name != "bitcoin" {
+		t.Errorf("CanonicalName(bitcoin): unexpected error %v", err)
+	}
+}
+
+// TestNetworkFlag ensures NetworkFlag works as a command line flag, a text
+// unmarshaler, and with environment variables.
+func TestNetworkFlag(t *testing.T) {
+	netFlag := rddnet.NewNetworkFlag(&rddnet.MainNetParams)
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	fs.Var(netFlag, "net", "network")
+
+	if err := fs.Parse([]string{"-net", "testnet"}); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if netFlag.Params != &rddnet.TestNet3Params {
+		t.Errorf("flag selected %v, want testnet3", netFlag)
+	}
+	if err := fs.Parse([]string{"-net=nonet"}); err == nil {
+		t.Errorf("Parse of unknown network succeeded")
+	}
+	if netFlag.Params != &rddnet.TestNet3Params {
+		t.Errorf("failed Set changed the selection to %v", netFlag)
+	}
+
+	var cfg struct {
+		Net *rddnet.NetworkFlag
+	}
+	if err := json.Unmarshal([]byte(`{"Net": "simnet"}`), &cfg); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if cfg.Net.Params != &rddnet.SimNetParams {
+		t.Errorf("unmarshal selected %v, want simnet", cfg.Net)
+	}
+	b, err := json.Marshal(cfg)
+	if err != nil || string(b) != `{"Net":"simnet"}` {
+		t.Errorf("Marshal: got %s, %v", b, err)
+	}
+	err = json.Unmarshal([]byte(`{"Net": "nonet"}`), &cfg)
+	if err == nil || !strings.Contains(err.Error(), "valid networks") {
+		t.Errorf("Unmarshal of unknown network: got error %v", err)
+	}
+
+	const envKey = "RDDNET_TEST_NETWORK"
+	netFlag = rddnet.NewNetworkFlag(&rddnet.MainNetParams)
+	t.Setenv(envKey, "")
+	if err := netFlag.SetFromEnv(envKey); err != nil ||
+		netFlag.Params != &rddnet.MainNetParams {
+
+		t.Errorf("SetFromEnv of empty variable: got %v, %v", netFlag, err)
+	}
+	t.Setenv(envKey, "regtest")
+	if err := netFlag.SetFromEnv(envKey); err != nil ||
+		netFlag.Params != &rddnet.RegressionNetParams {
+
+		t.Errorf("SetFromEnv: got %v, %v want regtest", netFlag, err)
+	}
+	t.Setenv(envKey, "nonet")
+	err = netFlag.SetFromEnv(envKey)
+	if !errors.As(err, new(rddnet.UnknownNetNameError)) ||
+		!strings.Contains(err.Error(), envKey) {
+
+		t.Errorf("SetFromEnv of unknown network: got error %v", err)
+	}
+}
@@ -7,6 +7,7 @@ package rddnet
 import (
 	"errors"
 	"math/big"
+	"strings"
 
 	"github.com/reddcoin-project/rddwire"
 )
@@ -56,6 +57,19 @@ type Params struct {
 	Net         rddwire.ReddcoinNet
 	DefaultPort string
 
+	// Default ports of the peer-to-peer, RPC, and websocket notification
+	// servers of nodes.  P2PPort is DefaultPort as a number, and both must
+	// be kept in sync.  A WSPort of zero means notifications are served by
+	// the RPC server.  Every default network sets P2PPort and RPCPort.
+	P2PPort uint16
+	RPCPort uint16
+	WSPort  uint16
+
+	// Other names the network is known by, such as those used by other
+	// Reddcoin software.  Names and aliases must be unique across all
+	// registered networks and are compared case insensitively.
+	Aliases []string
+
 	// Chain parameters
 	GenesisBlock           *rddwire.MsgBlock
 	GenesisHash            *rddwire.ShaHash
@@ -64,9 +78,46 @@ type Params struct {
 	SubsidyHalvingInterval int32
 	ResetMinDifficulty     bool
 
+	// Lowest version of transactions which use the PoSV format, which
+	// serializes the transaction timestamp.  Zero means the network only
+	// uses the legacy format.
+	PoSVTxVersion int32
+
+	// Number of blocks which must follow the block containing a coinbase
+	// or proof-of-stake-velocity coinstake transaction before its outputs
+	// may be spent.
+	CoinbaseMaturity  uint16
+	CoinstakeMaturity uint16
+
+	// Maximum amount in satoshi of a transaction output or the sum of the
+	// outputs of a transaction.
+	MaxMoney int64
+
+	// Block size and cost limits ordered from oldest to newest by the
+	// height they activate at.
+	BlockLimits []BlockLimits
+
 	// Checkpoints ordered from oldest to newest.
 	Checkpoints []Checkpoint
 
+	// Headers of checkpointed blocks ordered from oldest to newest.  They
+	// are optional and only used to let light clients start syncing
+	// headers from a recent block.  The default networks do not ship a
+	// snapshot past the genesis block yet, so light clients of them must
+	// still download every header.
+	HeaderSnapshots []HeaderSnapshot
+
+	// Serialized secp256k1 public keys of the checkpoint masters allowed to
+	// sign sync checkpoints for the network.  Keys may be in the compressed
+	// or uncompressed format.  No keys means the network does not use sync
+	// checkpoints.
+	CheckpointPubKeys [][]byte
+
+	// Script which the solution committed to by every block after the
+	// genesis block must satisfy on signets as described in BIP0325.  No
+	// challenge means the network is not a signet.
+	SignetChallenge []byte
+
 	// Reject version 1 blocks once a majority of the network has upgraded.
 	// This is part of BIP0034.
 	BlockV1RejectNumRequired uint64
@@ -77,7 +128,23 @@ type Params struct {
 	CoinbaseBlockHeightNumRequired uint64
 	CoinbaseBlockHeightNumToCheck  uint64
 
-	// Mempool parameters
+	// Peer-to-peer protocol parameters.  Peers which negotiate a protocol
+	// version below MinProtocolVersion are disconnected, and each feature
+	// in FeatureVersions is only used with peers which negotiate at least
+	// the version it maps to.
+	MinProtocolVersion uint32
+	FeatureVersions    map[ProtocolFeature]uint32
+
+	// Default relay and mining policy.  It does not affect consensus and
+	// may be overridden with WithPolicy.
+	Policy Policy
+
+	// Deprecated: RelayNonStdTxs mirrors Policy.RelayNonStdTxs for callers
+	// written before the policy was split out and will be removed in the
+	// future.  Read Policy.RelayNonStdTxs instead.  Networks must set both
+	// fields to the same value, which Register checks, and WithPolicy keeps
+	// them in sync.  Register fills Policy.RelayNonStdTxs from this field
+	// when Policy is the zero value.
 	RelayNonStdTxs bool
 
 	// Address encoding magics
@@ -85,6 +152,11 @@ type Params struct {
 	ScriptHashAddrID byte // First byte of a P2SH address
 	PrivateKeyID     byte // First byte of a WIF private key
 
+	// Human-readable part for Bech32 encoded segwit addresses, as defined
+	// in BIP0173.  An empty value means the network does not define native
+	// segwit addresses.
+	Bech32HRPSegwit string
+
 	// BIP32 hierarchical deterministic extended key magics
 	HDPrivateKeyID [4]byte
 	HDPublicKeyID  [4]byte
@@ -99,6 +171,9 @@ var MainNetParams = Params{
 	Name:        "mainnet",
 	Net:         rddwire.MainNet,
 	DefaultPort: "45444",
+	P2PPort:     45444,
+	RPCPort:     45443,
+	Aliases:     []string{"main"},
 
 	// Chain parameters
 	GenesisBlock:           &genesisBlock,
@@ -106,6 +181,18 @@ var MainNetParams = Params{
 	PowLimit:               mainPowLimit,
 	PowLimitBits:           0x1e0fffff,
 	ResetMinDifficulty:     false,
+	PoSVTxVersion:          2,
+	CoinbaseMaturity:       50,
+	CoinstakeMaturity:      50,
+	MaxMoney:               MaxSatoshi,
+
+	// Block size and cost limits ordered by activation height.  Later
+	// changes must be appended with the height they activate at rather
+	// than replacing the limits of the genesis block.
+	BlockLimits: []BlockLimits{
+		{Height: 0, MaxSize: DefaultMaxBlockSize,
+			MaxSigOps: DefaultMaxBlockSigOps},
+	},
 
 	// Checkpoints ordered from oldest to newest.
 	Checkpoints: []Checkpoint{
@@ -128,6 +215,13 @@ var MainNetParams = Params{
 		{244999, newShaHashFromStr("0b7bb56edfae2f2f1e71ac39daab16614fccf1a1e02c58d4169521d76d880b42")},		
 	},
 
+	// Header snapshots ordered from oldest to newest.  The work of the
+	// genesis block is 2^256 / (target+1) for its bits 0x1e0ffff0.  No
+	// snapshot past the genesis block ships yet.
+	HeaderSnapshots: []HeaderSnapshot{
+		{Height: 0, Header: genesisBlock.Header, Work: big.NewInt(1048592)},
+	},
+
 	// Reject version 1 blocks once a majority of the network has upgraded.
 	// 95% (950 / 1000)
 	// This is part of BIP0034.
@@ -141,7 +235,26 @@ var MainNetParams = Params{
 	CoinbaseBlockHeightNumRequired: 750,
 	CoinbaseBlockHeightNumToCheck:  1000,
 
-	// Mempool parameters
+	// Peer-to-peer protocol parameters
+	MinProtocolVersion: 70002,
+	FeatureVersions: map[ProtocolFeature]uint32{
+		FeatureSendHeaders:   70012,
+		FeatureFeeFilter:     70013,
+		FeatureCompactBlocks: 70014,
+	},
+
+	// Default relay and mining policy
+	Policy: Policy{
+		RelayNonStdTxs:      false,
+		MinRelayTxFee:       100000,
+		DustThreshold:       SatoshiPerReddcent,
+		MaxStandardTxSize:   100000,
+		MaxDataCarrierSize:  80,
+		EnableRBF:           false,
+		DefaultBlockMinSize: 0,
+	},
+
+	// Deprecated mirror of Policy.RelayNonStdTxs
 	RelayNonStdTxs: false,
 
 	// Address encoding magics
@@ -149,6 +262,10 @@ var MainNetParams = Params{
 	ScriptHashAddrID: 0x05, // starts with 3
 	PrivateKeyID:     0xbd, // starts with V (compressed)
 
+	// Human-readable part for Bech32 encoded segwit addresses, as defined in
+	// BIP0173.
+	Bech32HRPSegwit: "rdd", // always rdd for main net
+
 	// BIP32 hierarchical deterministic extended key magics
 	HDPrivateKeyID: [4]byte{0x04, 0x88, 0xad, 0xe4}, // starts with xprv
 	HDPublicKeyID:  [4]byte{0x04, 0x88, 0xb2, 0x1e}, // starts with xpub
@@ -165,6 +282,9 @@ var RegressionNetParams = Params{
 	Name:        "regtest",
 	Net:         rddwire.TestNet,
 	DefaultPort: "18444",
+	P2PPort:     18444,
+	RPCPort:     18443,
+	Aliases:     []string{"regression"},
 
 	// Chain parameters
 	GenesisBlock:           &regTestGenesisBlock,
@@ -172,6 +292,16 @@ var RegressionNetParams = Params{
 	PowLimit:               regressionPowLimit,
 	PowLimitBits:           0x207fffff,
 	ResetMinDifficulty:     true,
+	PoSVTxVersion:          2,
+	CoinbaseMaturity:       50,
+	CoinstakeMaturity:      50,
+	MaxMoney:               MaxSatoshi,
+
+	// Block size and cost limits ordered by activation height.
+	BlockLimits: []BlockLimits{
+		{Height: 0, MaxSize: DefaultMaxBlockSize,
+			MaxSigOps: DefaultMaxBlockSigOps},
+	},
 
 	// Checkpoints ordered from oldest to newest.
 	Checkpoints: nil,
@@ -189,7 +319,26 @@ var RegressionNetParams = Params{
 	CoinbaseBlockHeightNumRequired: 51,
 	CoinbaseBlockHeightNumToCheck:  100,
 
-	// Mempool parameters
+	// Peer-to-peer protocol parameters
+	MinProtocolVersion: 70002,
+	FeatureVersions: map[ProtocolFeature]uint32{
+		FeatureSendHeaders:   70012,
+		FeatureFeeFilter:     70013,
+		FeatureCompactBlocks: 70014,
+	},
+
+	// Default relay and mining policy
+	Policy: Policy{
+		RelayNonStdTxs:      true,
+		MinRelayTxFee:       100000,
+		DustThreshold:       SatoshiPerReddcent,
+		MaxStandardTxSize:   100000,
+		MaxDataCarrierSize:  80,
+		EnableRBF:           true,
+		DefaultBlockMinSize: 0,
+	},
+
+	// Deprecated mirror of Policy.RelayNonStdTxs
 	RelayNonStdTxs: true,
 
 	// Address encoding magics
@@ -197,6 +346,10 @@ var RegressionNetParams = Params{
 	ScriptHashAddrID: 0xc4, // starts with 2
 	PrivateKeyID:     0xef, // starts with 9 (uncompressed) or c (compressed)
 
+	// Human-readable part for Bech32 encoded segwit addresses, as defined in
+	// BIP0173.
+	Bech32HRPSegwit: "rrdd", // always rrdd for reg test net
+
 	// BIP32 hierarchical deterministic extended key magics
 	HDPrivateKeyID: [4]byte{0x04, 0x35, 0x83, 0x94}, // starts with tprv
 	HDPublicKeyID:  [4]byte{0x04, 0x35, 0x87, 0xcf}, // starts with tpub
@@ -213,6 +366,9 @@ var TestNet3Params = Params{
 	Name:        "testnet3",
 	Net:         rddwire.TestNet3,
 	DefaultPort: "18333",
+	P2PPort:     18333,
+	RPCPort:     55443,
+	Aliases:     []string{"testnet", "test"},
 
 	// Chain parameters
 	GenesisBlock:           &testNet3GenesisBlock,
@@ -220,10 +376,28 @@ var TestNet3Params = Params{
 	PowLimit:               testNet3PowLimit,
 	PowLimitBits:           0x1d00ffff,
 	ResetMinDifficulty:     true,
+	PoSVTxVersion:          2,
+	CoinbaseMaturity:       50,
+	CoinstakeMaturity:      50,
+	MaxMoney:               MaxSatoshi,
+
+	// Block size and cost limits ordered by activation height.
+	BlockLimits: []BlockLimits{
+		{Height: 0, MaxSize: DefaultMaxBlockSize,
+			MaxSigOps: DefaultMaxBlockSigOps},
+	},
 
 	// Checkpoints ordered from oldest to newest.
 	Checkpoints: nil,
 
+	// Header snapshots ordered from oldest to newest.  The work of the
+	// genesis block is 2^256 / (target+1) for its bits 0x1d00ffff.  No
+	// snapshot past the genesis block ships yet.
+	HeaderSnapshots: []HeaderSnapshot{
+		{Height: 0, Header: testNet3GenesisBlock.Header,
+			Work: big.NewInt(4295032833)},
+	},
+
 	// Reject version 1 blocks once a majority of the network has upgraded.
 	// 75% (75 / 100)
 	// This is part of BIP0034.
@@ -237,7 +411,26 @@ var TestNet3Params = Params{
 	CoinbaseBlockHeightNumRequired: 51,
 	CoinbaseBlockHeightNumToCheck:  100,
 
-	// Mempool parameters
+	// Peer-to-peer protocol parameters
+	MinProtocolVersion: 70002,
+	FeatureVersions: map[ProtocolFeature]uint32{
+		FeatureSendHeaders:   70012,
+		FeatureFeeFilter:     70013,
+		FeatureCompactBlocks: 70014,
+	},
+
+	// Default relay and mining policy
+	Policy: Policy{
+		RelayNonStdTxs:      true,
+		MinRelayTxFee:       100000,
+		DustThreshold:       SatoshiPerReddcent,
+		MaxStandardTxSize:   100000,
+		MaxDataCarrierSize:  80,
+		EnableRBF:           true,
+		DefaultBlockMinSize: 0,
+	},
+
+	// Deprecated mirror of Policy.RelayNonStdTxs
 	RelayNonStdTxs: true,
 
 	// Address encoding magics
@@ -245,6 +438,10 @@ var TestNet3Params = Params{
 	ScriptHashAddrID: 0xc4, // starts with 2
 	PrivateKeyID:     0xef, // starts with 9 (uncompressed) or c (compressed)
 
+	// Human-readable part for Bech32 encoded segwit addresses, as defined in
+	// BIP0173.
+	Bech32HRPSegwit: "trdd", // always trdd for test net
+
 	// BIP32 hierarchical deterministic extended key magics
 	HDPrivateKeyID: [4]byte{0x04, 0x35, 0x83, 0x94}, // starts with tprv
 	HDPublicKeyID:  [4]byte{0x04, 0x35, 0x87, 0xcf}, // starts with tpub
@@ -265,6 +462,9 @@ var SimNetParams = Params{
 	Name:        "simnet",
 	Net:         rddwire.SimNet,
 	DefaultPort: "18555",
+	P2PPort:     18555,
+	RPCPort:     18556,
+	Aliases:     []string{"sim"},
 
 	// Chain parameters
 	GenesisBlock:           &simNetGenesisBlock,
@@ -272,6 +472,16 @@ var SimNetParams = Params{
 	PowLimit:               simNetPowLimit,
 	PowLimitBits:           0x207fffff,
 	ResetMinDifficulty:     true,
+	PoSVTxVersion:          2,
+	CoinbaseMaturity:       50,
+	CoinstakeMaturity:      50,
+	MaxMoney:               MaxSatoshi,
+
+	// Block size and cost limits ordered by activation height.
+	BlockLimits: []BlockLimits{
+		{Height: 0, MaxSize: DefaultMaxBlockSize,
+			MaxSigOps: DefaultMaxBlockSigOps},
+	},
 
 	// Checkpoints ordered from oldest to newest.
 	Checkpoints: nil,
@@ -287,7 +497,26 @@ var SimNetParams = Params{
 	CoinbaseBlockHeightNumRequired: 51,
 	CoinbaseBlockHeightNumToCheck:  100,
 
-	// Mempool parameters
+	// Peer-to-peer protocol parameters
+	MinProtocolVersion: 70002,
+	FeatureVersions: map[ProtocolFeature]uint32{
+		FeatureSendHeaders:   70012,
+		FeatureFeeFilter:     70013,
+		FeatureCompactBlocks: 70014,
+	},
+
+	// Default relay and mining policy
+	Policy: Policy{
+		RelayNonStdTxs:      true,
+		MinRelayTxFee:       100000,
+		DustThreshold:       SatoshiPerReddcent,
+		MaxStandardTxSize:   100000,
+		MaxDataCarrierSize:  80,
+		EnableRBF:           true,
+		DefaultBlockMinSize: 0,
+	},
+
+	// Deprecated mirror of Policy.RelayNonStdTxs
 	RelayNonStdTxs: true,
 
 	// Address encoding magics
@@ -295,6 +524,10 @@ var SimNetParams = Params{
 	ScriptHashAddrID: 0x7b, // starts with s
 	PrivateKeyID:     0x64, // starts with 4 (uncompressed) or F (compressed)
 
+	// Human-readable part for Bech32 encoded segwit addresses, as defined in
+	// BIP0173.
+	Bech32HRPSegwit: "srdd", // always srdd for sim net
+
 	// BIP32 hierarchical deterministic extended key magics
 	HDPrivateKeyID: [4]byte{0x04, 0x20, 0xb9, 0x00}, // starts with sprv
 	HDPublicKeyID:  [4]byte{0x04, 0x20, 0xbd, 0x3a}, // starts with spub
@@ -314,14 +547,46 @@ var (
 	// is intended to identify the network for a hierarchical deterministic
 	// private extended key is not registered.
 	ErrUnknownHDKeyID = errors.New("unknown hd private extended key bytes")
+
+	// ErrInvalidBech32HRP describes an error where the parameters for a
+	// Reddcoin network could not be set due to the human-readable part for
+	// Bech32 segwit addresses not being valid according to BIP0173.
+	ErrInvalidBech32HRP = errors.New("invalid bech32 human-readable part")
+
+	// ErrDuplicateBech32HRP describes an error where the parameters for a
+	// Reddcoin network could not be set due to the human-readable part for
+	// Bech32 segwit addresses already being used by a standard network or a
+	// previously-registered network.  Unlike the base58 address magics,
+	// human-readable parts must uniquely identify a network.
+	ErrDuplicateBech32HRP = errors.New("duplicate bech32 human-readable part")
+
+	// ErrUnknownBech32HRP describes an error where the provided
+	// human-readable part is not used by any default or registered network.
+	ErrUnknownBech32HRP = errors.New("unknown bech32 human-readable part")
+
+	// ErrDuplicateNetName describes an error where the parameters for a
+	// Reddcoin network could not be set due to its name or one of its
+	// aliases already naming a standard network or a previously-registered
+	// network.
+	ErrDuplicateNetName = errors.New("duplicate Reddcoin network name or " +
+		"alias")
 )
 
 var (
-	registeredNets = map[rddwire.ReddcoinNet]struct{}{
-		MainNetParams.Net:       struct{}{},
-		TestNet3Params.Net:      struct{}{},
-		RegressionNetParams.Net: struct{}{},
-		SimNetParams.Net:        struct{}{},
+	// registeredParams holds the default and registered networks in the
+	// order in which they were registered.
+	registeredParams = []*Params{
+		&MainNetParams,
+		&TestNet3Params,
+		&RegressionNetParams,
+		&SimNetParams,
+	}
+
+	registeredNets = map[rddwire.ReddcoinNet]*Params{
+		MainNetParams.Net:       &MainNetParams,
+		TestNet3Params.Net:      &TestNet3Params,
+		RegressionNetParams.Net: &RegressionNetParams,
+		SimNetParams.Net:        &SimNetParams,
 	}
 
 	pubKeyHashAddrIDs = map[byte]struct{}{
@@ -342,12 +607,98 @@ var (
 		TestNet3Params.HDPrivateKeyID: TestNet3Params.HDPublicKeyID[:],
 		SimNetParams.HDPrivateKeyID:   SimNetParams.HDPublicKeyID[:],
 	}
+
+	// netNames maps the lowercase names and aliases of the default and
+	// registered networks to their parameters.
+	netNames = func() map[string]*Params {
+		names := make(map[string]*Params)
+		for _, params := range registeredParams {
+			for _, name := range params.names() {
+				names[name] = params
+			}
+		}
+		return names
+	}()
+
+	bech32SegwitPrefixes = map[string]*Params{
+		MainNetParams.Bech32HRPSegwit:       &MainNetParams,
+		TestNet3Params.Bech32HRPSegwit:      &TestNet3Params,
+		RegressionNetParams.Bech32HRPSegwit: &RegressionNetParams,
+		SimNetParams.Bech32HRPSegwit:        &SimNetParams,
+	}
 )
 
+// normalizeBech32HRP returns the lowercase form of the passed human-readable
+// part.  It returns ErrInvalidBech32HRP if the human-readable part is empty,
+// longer than 83 characters, contains characters outside of the printable
+// US-ASCII range [33, 126], or is of mixed case, as required by BIP0173.
+func normalizeBech32HRP(hrp string) (string, error) {
+	if len(hrp) < 1 || len(hrp) > 83 {
+		return "", ErrInvalidBech32HRP
+	}
+	var hasLower, hasUpper bool
+	for i := 0; i < len(hrp); i++ {
+		c := hrp[i]
+		switch {
+		case c < 33 || c > 126:
+			return "", ErrInvalidBech32HRP
+		case c >= 'a' && c <= 'z':
+			hasLower = true
+		case c >= 'A' && c <= 'Z':
+			hasUpper = true
+		}
+	}
+	if hasLower && hasUpper {
+		return "", ErrInvalidBech32HRP
+	}
+	return strings.ToLower(hrp), nil
+}
+
+// VerifyBech32HRP checks that the human-readable part for Bech32 encoded
+// segwit addresses of the network is valid according to BIP0173 as Register
+// requires.  An empty human-readable part means the network does not support
+// segwit addresses and is valid.  ErrInvalidBech32HRP is returned otherwise.
+func (p *Params) VerifyBech32HRP() error {
+	if p.Bech32HRPSegwit == "" {
+		return nil
+	}
+	_, err := normalizeBech32HRP(p.Bech32HRPSegwit)
+	return err
+}
+
+// names returns the lowercase name and aliases of the network.  Empty names
+// are skipped.
+func (p *Params) names() []string {
+	names := make([]string, 0, 1+len(p.Aliases))
+	for _, name := range append([]string{p.Name}, p.Aliases...) {
+		if name != "" {
+			names = append(names, strings.ToLower(name))
+		}
+	}
+	return names
+}
+
 // Register registers the network parameters for a Reddcoin network.  This may
 // error with ErrDuplicateNet if the network is already registered (either
 // due to a previous Register call, or the network being one of the default
-// networks).
+// networks).  It may also error with ErrInvalidBech32HRP or
+// ErrDuplicateBech32HRP if the network defines a Bech32 human-readable part
+// which is invalid or is already used by another network, and with
+// ErrDuplicateNetName if its name or one of its aliases already names another
+// network or is repeated, with ErrInvalidPolicy if its default policy is
+// invalid or disagrees with the deprecated RelayNonStdTxs field, with
+// ErrInvalidCheckpointPubKey if one of its checkpoint master public keys is
+// invalid, and with ErrUnsupportedSignetChallenge if its signet challenge can
+// not be evaluated.  It may also error with a PortError, GenesisError, or
+// HeaderSnapshotError if its default ports, genesis block, or header snapshots
+// fail VerifyPorts, VerifyGenesis, or VerifyHeaderSnapshots.  VerifyGenesis is
+// skipped for networks which set neither GenesisBlock nor GenesisHash, while a
+// GenesisHash without a GenesisBlock is rejected.  Nothing is registered when
+// an error is returned.
+//
+// The policy of networks which leave Policy as the zero value is filled from
+// the deprecated RelayNonStdTxs field, so networks written before Policy was
+// added still register.
 //
 // Network parameters should be registered into this package by a main package
 // as early as possible.  Then, library packages may lookup networks or network
@@ -357,10 +708,74 @@ func Register(params *Params) error {
 	if _, ok := registeredNets[params.Net]; ok {
 		return ErrDuplicateNet
 	}
-	registeredNets[params.Net] = struct{}{}
+	var hrp string
+	if params.Bech32HRPSegwit != "" {
+		var err error
+		hrp, err = normalizeBech32HRP(params.Bech32HRPSegwit)
+		if err != nil {
+			return err
+		}
+		if _, ok := bech32SegwitPrefixes[hrp]; ok {
+			return ErrDuplicateBech32HRP
+		}
+	}
+	if err := params.VerifyPorts(); err != nil {
+		return err
+	}
+	// Networks written before Policy was split out only set the
+	// deprecated RelayNonStdTxs field, so their policy is filled from it.
+	policy := params.Policy
+	if policy == (Policy{}) {
+		policy.RelayNonStdTxs = params.RelayNonStdTxs
+	}
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	if params.RelayNonStdTxs != policy.RelayNonStdTxs {
+		return ErrInvalidPolicy
+	}
+	// Networks which set neither a genesis block nor its hash are not
+	// verified, while VerifyGenesis rejects a hash without a block.
+	if params.GenesisBlock != nil || params.GenesisHash != nil {
+		if err := params.VerifyGenesis(); err != nil {
+			return err
+		}
+	}
+	if err := params.VerifyHeaderSnapshots(); err != nil {
+		return err
+	}
+	if _, err := params.parseCheckpointPubKeys(); err != nil {
+		return err
+	}
+	if len(params.SignetChallenge) > 0 {
+		if _, err := parseSignetChallenge(params.SignetChallenge); err != nil {
+			return err
+		}
+	}
+	names := params.names()
+	for i, name := range names {
+		if _, ok := netNames[name]; ok {
+			return ErrDuplicateNetName
+		}
+		for _, other := range names[:i] {
+			if name == other {
+				return ErrDuplicateNetName
+			}
+		}
+	}
+
+	params.Policy = policy
+	registeredParams = append(registeredParams, params)
+	registeredNets[params.Net] = params
 	pubKeyHashAddrIDs[params.PubKeyHashAddrID] = struct{}{}
 	scriptHashAddrIDs[params.ScriptHashAddrID] = struct{}{}
 	hdPrivToPubKeyIDs[params.HDPrivateKeyID] = params.HDPublicKeyID[:]
+	if hrp != "" {
+		bech32SegwitPrefixes[hrp] = params
+	}
+	for _, name := range names {
+		netNames[name] = params
+	}
 	return nil
 }
 
@@ -386,6 +801,33 @@ func IsScriptHashAddrID(id byte) bool {
 	return ok
 }
 
+// IsBech32SegwitPrefix returns whether the passed human-readable part is known
+// to prefix a Bech32 encoded segwit address on any default or registered
+// network.  The comparison is case insensitive since Bech32 strings may be
+// entirely uppercase, however mixed case human-readable parts are never valid.
+// This is used when decoding an address string into a specific address type.
+func IsBech32SegwitPrefix(hrp string) bool {
+	_, err := ParamsForBech32HRP(hrp)
+	return err == nil
+}
+
+// ParamsForBech32HRP returns the parameters of the default or registered
+// network which uses the passed human-readable part for Bech32 encoded segwit
+// addresses.  The comparison is case insensitive.  When no network uses the
+// human-readable part, or it is not valid, the ErrUnknownBech32HRP error will
+// be returned.
+func ParamsForBech32HRP(hrp string) (*Params, error) {
+	hrp, err := normalizeBech32HRP(hrp)
+	if err != nil {
+		return nil, ErrUnknownBech32HRP
+	}
+	params, ok := bech32SegwitPrefixes[hrp]
+	if !ok {
+		return nil, ErrUnknownBech32HRP
+	}
+	return params, nil
+}
+
 // HDPrivateKeyToPublicKeyID accepts a private hierarchical deterministic
 // extended key id and returns the associated public key id.  When the provided
 // id is not registered, the ErrUnknownHDKeyID error will be returned.
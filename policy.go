@@ -0,0 +1,80 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet
+
+import (
+	"errors"
+)
+
+// ErrInvalidPolicy describes an error where a relay and mining policy holds a
+// negative fee or dust threshold.
+var ErrInvalidPolicy = errors.New("invalid relay and mining policy")
+
+// Policy defines the local relay and mining policy of a node.  Unlike the rest
+// of the network parameters, the policy does not need to agree between nodes,
+// so each network only defines defaults which operators may override without
+// affecting consensus or the fingerprint of the network.
+type Policy struct {
+	// RelayNonStdTxs is whether transactions which are not standard are
+	// accepted into the mempool and relayed.
+	RelayNonStdTxs bool `json:"relayNonStdTxs"`
+
+	// MinRelayTxFee is the minimum fee in satoshi per kilobyte of a
+	// transaction which is accepted into the mempool and relayed.
+	MinRelayTxFee int64 `json:"minRelayTxFee"`
+
+	// DustThreshold is the smallest output amount in satoshi of a
+	// standard transaction.
+	DustThreshold int64 `json:"dustThreshold"`
+
+	// MaxStandardTxSize is the largest serialized size in bytes of a
+	// standard transaction.
+	MaxStandardTxSize uint32 `json:"maxStandardTxSize"`
+
+	// MaxDataCarrierSize is the largest number of bytes pushed by a
+	// standard null data output.  Zero means null data outputs are not
+	// standard.
+	MaxDataCarrierSize uint32 `json:"maxDataCarrierSize"`
+
+	// EnableRBF is whether mempool transactions signaling replaceability
+	// as defined in BIP0125 may be replaced by transactions paying a
+	// higher fee.
+	EnableRBF bool `json:"enableRBF"`
+
+	// DefaultBlockMinSize is the default minimum size in bytes of blocks
+	// created by the miner regardless of the fees of the transactions
+	// included.
+	DefaultBlockMinSize uint32 `json:"defaultBlockMinSize"`
+}
+
+// Validate returns ErrInvalidPolicy when the policy holds a negative fee or
+// dust threshold.
+func (p *Policy) Validate() error {
+	if p.MinRelayTxFee < 0 || p.DustThreshold < 0 {
+		return ErrInvalidPolicy
+	}
+	return nil
+}
+
+// WithPolicy returns a deep copy of the network parameters which uses the
+// passed relay and mining policy, such as the default policy of the network
+// with some fields overridden from configuration.  The deprecated
+// RelayNonStdTxs field is updated to match the policy.  All other fields, and
+// therefore the fingerprint, are the same as those of the parameters.
+//
+//	policy := rddnet.MainNetParams.Policy
+//	if err := json.Unmarshal(cfg, &policy); err != nil {
+//		return err
+//	}
+//	params, err := rddnet.MainNetParams.WithPolicy(policy)
+func (p *Params) WithPolicy(policy Policy) (*Params, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	clone := p.Clone()
+	clone.Policy = policy
+	clone.RelayNonStdTxs = policy.RelayNonStdTxs
+	return clone, nil
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/reddcoin-project/rddnet"
+)
+
+// TestWithPolicy ensures policies overridden from configuration only change the
+// policy of the returned parameters.
+func TestWithPolicy(t *testing.T) {
+	orig := rddnet.MainNetParams.Policy
+
+	policy := rddnet.MainNetParams.Policy
+	cfg := []byte(`{"minRelayTxFee": 5000, "enableRBF": true}`)
+	if err := json.Unmarshal(cfg, &policy); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+	params, err := rddnet.MainNetParams.WithPolicy(policy)
+	if err != nil {
+		t.Fatalf("WithPolicy: unexpected error %v", err)
+	}
+
+	want := orig
+	want.MinRelayTxFee = 5000
+	want.EnableRBF = true
+	if params.Policy != want {
+		t.Errorf("WithPolicy: got policy %+v want %+v", params.Policy,
+			want)
+	}
+	if rddnet.MainNetParams.Policy != orig {
+		t.Errorf("WithPolicy modified the default policy: %+v",
+			rddnet.MainNetParams.Policy)
+	}
+	if params.Fingerprint() != rddnet.MainNetParams.Fingerprint() {
+		t.Error("WithPolicy changed the fingerprint")
+	}
+	for _, diff := range rddnet.DiffParams(&rddnet.MainNetParams, params) {
+		if diff.Class != rddnet.FieldPolicy {
+			t.Errorf("WithPolicy changed non-policy field %v", diff)
+		}
+	}
+
+	invalid := []rddnet.Policy{
+		{MinRelayTxFee: -1},
+		{DustThreshold: -1},
+	}
+	for _, policy := range invalid {
+		_, err := rddnet.MainNetParams.WithPolicy(policy)
+		if err != rddnet.ErrInvalidPolicy {
+			t.Errorf("WithPolicy(%+v): got error %v want %v", policy,
+				err, rddnet.ErrInvalidPolicy)
+		}
+	}
+
+	// Networks with an invalid default policy can not be registered.
+	badNet := rddnet.Params{
+		Name:             "badpolicynet",
+		Net:              0xffffffc0,
+		PubKeyHashAddrID: 0x9e,
+		ScriptHashAddrID: 0xf8,
+		Policy:           rddnet.Policy{DustThreshold: -1},
+	}
+	if err := rddnet.TstRegister(t, &badNet); err != rddnet.ErrInvalidPolicy {
+		t.Errorf("Register: got error %v want %v", err,
+			rddnet.ErrInvalidPolicy)
+	}
+}
+
+// TestDeprecatedRelayNonStdTxs ensures the deprecated RelayNonStdTxs field
+// mirrors the policy of the default networks and of parameters returned by
+// WithPolicy, and that networks where the two disagree can not be registered.
+func TestDeprecatedRelayNonStdTxs(t *testing.T) {
+	for _, params := range defaultNets {
+		if params.RelayNonStdTxs != params.Policy.RelayNonStdTxs {
+			t.Errorf("%s: RelayNonStdTxs %v does not match the "+
+				"policy", params.Name, params.RelayNonStdTxs)
+		}
+	}
+
+	policy := rddnet.MainNetParams.Policy
+	policy.RelayNonStdTxs = true
+	params, err := rddnet.MainNetParams.WithPolicy(policy)
+	if err != nil {
+		t.Fatalf("WithPolicy: unexpected error %v", err)
+	}
+	if !params.RelayNonStdTxs {
+		t.Error("WithPolicy did not update RelayNonStdTxs")
+	}
+	if rddnet.MainNetParams.RelayNonStdTxs {
+		t.Error("WithPolicy modified MainNetParams.RelayNonStdTxs")
+	}
+
+	mismatched := rddnet.Params{
+		Name:             "nonstdmismatchnet",
+		Net:              0xffffffc1,
+		PubKeyHashAddrID: 0x9e,
+		ScriptHashAddrID: 0xf8,
+		Policy:           rddnet.Policy{MinRelayTxFee: 1000},
+		RelayNonStdTxs:   true,
+	}
+	if err := rddnet.TstRegister(t, &mismatched); err != rddnet.ErrInvalidPolicy {
+		t.Errorf("Register: got error %v want %v", err,
+			rddnet.ErrInvalidPolicy)
+	}
+
+	// Networks written before Policy was split out only set the deprecated
+	// field, which fills their policy.
+	legacy := rddnet.Params{
+		Name:             "nonstdlegacynet",
+		Net:              0xffffffc2,
+		PubKeyHashAddrID: 0x9e,
+		ScriptHashAddrID: 0xf8,
+		RelayNonStdTxs:   true,
+	}
+	if err := rddnet.TstRegister(t, &legacy); err != nil {
+		t.Fatalf("Register legacy network: unexpected error %v", err)
+	}
+	if !legacy.Policy.RelayNonStdTxs {
+		t.Error("Register did not fill Policy.RelayNonStdTxs from the " +
+			"deprecated field")
+	}
+	registered, err := rddnet.ParamsForName("nonstdlegacynet")
+	if err != nil || !registered.Policy.RelayNonStdTxs {
+		t.Errorf("ParamsForName: got %v, %v with the policy filled",
+			registered, err)
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// PortError describes an error where the default ports of a network are
+// invalid or inconsistent.
+type PortError struct {
+	// Field is the name of the invalid port field from the Params struct,
+	// such as RPCPort.
+	Field string
+
+	// Description describes the problem.
+	Description string
+}
+
+// Error satisfies the error interface and prints human-readable errors.
+func (e PortError) Error() string {
+	return fmt.Sprintf("invalid default port: %s: %s", e.Field,
+		e.Description)
+}
+
+// VerifyPorts checks that the default ports of the network are valid.  A
+// DefaultPort must be a decimal port in the range 1 to 65535 which matches
+// P2PPort when both are set, and no two servers may share a port.  Empty and
+// zero ports are not set, so parameters which predate the typed ports remain
+// valid.  A PortError describing the first problem is returned.
+func (p *Params) VerifyPorts() error {
+	if p.DefaultPort != "" {
+		port, err := strconv.ParseUint(p.DefaultPort, 10, 16)
+		if err != nil || port == 0 {
+			return PortError{"DefaultPort", fmt.Sprintf("%q is not "+
+				"a port in the range 1 to 65535", p.DefaultPort)}
+		}
+		if p.P2PPort != 0 && uint16(port) != p.P2PPort {
+			return PortError{"DefaultPort", fmt.Sprintf("%q does "+
+				"not match P2PPort %d", p.DefaultPort,
+				p.P2PPort)}
+		}
+	}
+	if p.RPCPort != 0 && p.RPCPort == p.P2PPort {
+		return PortError{"RPCPort", fmt.Sprintf("port %d is also the "+
+			"peer-to-peer port", p.RPCPort)}
+	}
+	if p.WSPort != 0 && (p.WSPort == p.P2PPort || p.WSPort == p.RPCPort) {
+		return PortError{"WSPort", fmt.Sprintf("port %d is also used "+
+			"by another server", p.WSPort)}
+	}
+	return nil
+}
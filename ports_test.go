@@ -0,0 +1,96 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/reddcoin-project/rddnet"
+	"github.com/reddcoin-project/rddwire"
+)
+
+// TestDefaultNetPorts ensures every default network has valid typed ports
+// which match its DefaultPort.
+func TestDefaultNetPorts(t *testing.T) {
+	for _, params := range defaultNets {
+		if err := params.VerifyPorts(); err != nil {
+			t.Errorf("%s: VerifyPorts: %v", params.Name, err)
+		}
+		if params.P2PPort == 0 || params.RPCPort == 0 {
+			t.Errorf("%s: got P2PPort %d and RPCPort %d", params.Name,
+				params.P2PPort, params.RPCPort)
+		}
+		if strconv.Itoa(int(params.P2PPort)) != params.DefaultPort {
+			t.Errorf("%s: P2PPort %d does not match DefaultPort %q",
+				params.Name, params.P2PPort, params.DefaultPort)
+		}
+	}
+}
+
+// TestVerifyPorts ensures invalid and inconsistent ports are rejected, both by
+// VerifyPorts and by Register.
+func TestVerifyPorts(t *testing.T) {
+	tests := []struct {
+		name      string
+		mutate    func(p *rddnet.Params)
+		wantField string
+	}{
+		{"valid", func(p *rddnet.Params) {}, ""},
+		{"no ports", func(p *rddnet.Params) {
+			p.DefaultPort, p.P2PPort, p.RPCPort = "", 0, 0
+		}, ""},
+		{"string port only", func(p *rddnet.Params) {
+			p.P2PPort, p.RPCPort = 0, 0
+		}, ""},
+		{"websocket port", func(p *rddnet.Params) { p.WSPort = 1 }, ""},
+		{"named port", func(p *rddnet.Params) {
+			p.DefaultPort = "rdd"
+		}, "DefaultPort"},
+		{"zero port", func(p *rddnet.Params) {
+			p.DefaultPort = "0"
+		}, "DefaultPort"},
+		{"port too large", func(p *rddnet.Params) {
+			p.DefaultPort = "65536"
+		}, "DefaultPort"},
+		{"mismatched port", func(p *rddnet.Params) {
+			p.P2PPort++
+		}, "DefaultPort"},
+		{"shared rpc port", func(p *rddnet.Params) {
+			p.RPCPort = p.P2PPort
+		}, "RPCPort"},
+		{"shared websocket port", func(p *rddnet.Params) {
+			p.WSPort = p.RPCPort
+		}, "WSPort"},
+	}
+	for i, test := range tests {
+		params := rddnet.SimNetParams.Clone()
+		params.Name = "portnet" + strconv.Itoa(i)
+		params.Net = 0xffffff80 + rddwire.ReddcoinNet(i)
+		params.Aliases = nil
+		params.Bech32HRPSegwit = ""
+		test.mutate(params)
+
+		err := params.VerifyPorts()
+		if test.wantField == "" {
+			if err != nil {
+				t.Errorf("%s: VerifyPorts: unexpected error %v",
+					test.name, err)
+			}
+			continue
+		}
+		if perr, ok := err.(rddnet.PortError); !ok ||
+			perr.Field != test.wantField {
+
+			t.Errorf("%s: VerifyPorts: got error %v want a "+
+				"PortError for %s", test.name, err,
+				test.wantField)
+		}
+		if _, ok := rddnet.TstRegister(t, params).(rddnet.PortError); !ok {
+			t.Errorf("%s: Register did not return a PortError",
+				test.name)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ProtocolFeature identifies an optional peer-to-peer protocol feature which is
+// only enabled when both peers negotiate a protocol version at or above the
+// version the network gates it on.
+type ProtocolFeature int
+
+// These constants define the protocol features which may be gated per network.
+const (
+	// FeatureSendHeaders is the sendheaders message which asks peers to
+	// announce new blocks with headers rather than inventory vectors, as
+	// defined in BIP0130.
+	FeatureSendHeaders ProtocolFeature = iota
+
+	// FeatureFeeFilter is the feefilter message which asks peers not to
+	// relay transactions below a fee rate, as defined in BIP0133.
+	FeatureFeeFilter
+
+	// FeatureCompactBlocks is the compact block relay protocol, as defined
+	// in BIP0152.
+	FeatureCompactBlocks
+)
+
+// protocolFeatureStrings is a map of protocol features back to their constant
+// names for pretty printing.
+var protocolFeatureStrings = map[ProtocolFeature]string{
+	FeatureSendHeaders:   "FeatureSendHeaders",
+	FeatureFeeFilter:     "FeatureFeeFilter",
+	FeatureCompactBlocks: "FeatureCompactBlocks",
+}
+
+// String returns the ProtocolFeature in human-readable form.
+func (f ProtocolFeature) String() string {
+	if s, ok := protocolFeatureStrings[f]; ok {
+		return s
+	}
+	return fmt.Sprintf("Unknown ProtocolFeature (%d)", int(f))
+}
+
+// SupportsFeature returns whether peers which negotiated the passed protocol
+// version may use the passed feature on the network.  No feature is supported
+// below the minimum protocol version of the network, and features which the
+// network does not gate on a version are never supported.
+func (p *Params) SupportsFeature(pver uint32, feature ProtocolFeature) bool {
+	if pver < p.MinProtocolVersion {
+		return false
+	}
+	version, ok := p.FeatureVersions[feature]
+	return ok && pver >= version
+}
+
+// sortedFeatures returns the features gated by either of the passed tables in
+// ascending order.
+func sortedFeatures(a, b map[ProtocolFeature]uint32) []ProtocolFeature {
+	seen := make(map[ProtocolFeature]struct{}, len(a)+len(b))
+	features := make([]ProtocolFeature, 0, len(a)+len(b))
+	for _, table := range []map[ProtocolFeature]uint32{a, b} {
+		for feature := range table {
+			if _, ok := seen[feature]; ok {
+				continue
+			}
+			seen[feature] = struct{}{}
+			features = append(features, feature)
+		}
+	}
+	sort.Slice(features, func(i, j int) bool {
+		return features[i] < features[j]
+	})
+	return features
+}
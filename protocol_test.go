@@ -0,0 +1,84 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet_test
+
+import (
+	"testing"
+
+	"github.com/reddcoin-project/rddnet"
+)
+
+// TestSupportsFeature ensures protocol features are gated on both the minimum
+// protocol version of the network and the version of each feature.
+func TestSupportsFeature(t *testing.T) {
+	// A custom network with older nodes which gates compact blocks on an
+	// earlier version and does not support fee filters at all.
+	oldNet := rddnet.Params{
+		MinProtocolVersion: 209,
+		FeatureVersions: map[rddnet.ProtocolFeature]uint32{
+			rddnet.FeatureSendHeaders:   70012,
+			rddnet.FeatureCompactBlocks: 70003,
+		},
+	}
+
+	tests := []struct {
+		name    string
+		params  *rddnet.Params
+		pver    uint32
+		feature rddnet.ProtocolFeature
+		want    bool
+	}{
+		{"mainnet below minimum", &rddnet.MainNetParams, 70001,
+			rddnet.FeatureSendHeaders, false},
+		{"mainnet minimum", &rddnet.MainNetParams, 70002,
+			rddnet.FeatureSendHeaders, false},
+		{"mainnet sendheaders", &rddnet.MainNetParams, 70012,
+			rddnet.FeatureSendHeaders, true},
+		{"mainnet feefilter too old", &rddnet.MainNetParams, 70012,
+			rddnet.FeatureFeeFilter, false},
+		{"mainnet feefilter", &rddnet.MainNetParams, 70013,
+			rddnet.FeatureFeeFilter, true},
+		{"mainnet compact blocks", &rddnet.MainNetParams, 70014,
+			rddnet.FeatureCompactBlocks, true},
+		{"mainnet unknown feature", &rddnet.MainNetParams, 80000,
+			rddnet.ProtocolFeature(100), false},
+		{"old net below minimum", &oldNet, 208,
+			rddnet.FeatureCompactBlocks, false},
+		{"old net compact blocks", &oldNet, 70003,
+			rddnet.FeatureCompactBlocks, true},
+		{"old net no feefilter", &oldNet, 80000,
+			rddnet.FeatureFeeFilter, false},
+	}
+
+	for _, test := range tests {
+		got := test.params.SupportsFeature(test.pver, test.feature)
+		if got != test.want {
+			t.Errorf("%s: SupportsFeature(%d, %v): got %v want %v",
+				test.name, test.pver, test.feature, got, test.want)
+		}
+	}
+}
+
+// TestProtocolFeatureStringer tests the stringized output for the
+// ProtocolFeature type.
+func TestProtocolFeatureStringer(t *testing.T) {
+	tests := []struct {
+		in   rddnet.ProtocolFeature
+		want string
+	}{
+		{rddnet.FeatureSendHeaders, "FeatureSendHeaders"},
+		{rddnet.FeatureFeeFilter, "FeatureFeeFilter"},
+		{rddnet.FeatureCompactBlocks, "FeatureCompactBlocks"},
+		{0xff, "Unknown ProtocolFeature (255)"},
+	}
+
+	for i, test := range tests {
+		result := test.in.String()
+		if result != test.want {
+			t.Errorf("String #%d\n got: %s want: %s", i, result,
+				test.want)
+		}
+	}
+}
@@ -2,10 +2,13 @@ package rddnet_test
 
 import (
 	"bytes"
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 
 	. "github.com/reddcoin-project/rddnet"
+	"github.com/reddcoin-project/rddwire"
 )
 
 // Define some of the required parameters for a user-registered
@@ -378,3 +381,254 @@ func TestRegister(t *testing.T) {
 		}
 	}
 }
+
+// TestRegisterBech32HRP ensures the human-readable parts for Bech32 segwit
+// addresses are validated and must be unique across the default and registered
+// networks.
+func TestRegisterBech32HRP(t *testing.T) {
+	// newNet returns the parameters of a mock network with a unique magic
+	// and name for each test below and the passed human-readable part.
+	newNet := func(net uint32, hrp string) *Params {
+		return &Params{
+			Name:             fmt.Sprintf("hrpnet%x", net),
+			Net:              rddwire.ReddcoinNet(net),
+			PubKeyHashAddrID: 0x9e,
+			ScriptHashAddrID: 0xf8,
+			HDPrivateKeyID:   [4]byte{0x0a, 0x0b, 0x0c, 0x0d},
+			HDPublicKeyID:    [4]byte{0x0e, 0x0f, 0x10, 0x11},
+			Bech32HRPSegwit:  hrp,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		params *Params
+		err    error
+	}{
+		{
+			name:   "mainnet hrp",
+			params: newNet(0xfffffff0, MainNetParams.Bech32HRPSegwit),
+			err:    ErrDuplicateBech32HRP,
+		},
+		{
+			name:   "uppercase testnet3 hrp",
+			params: newNet(0xfffffff1, "TRDD"),
+			err:    ErrDuplicateBech32HRP,
+		},
+		{
+			name:   "mixed case",
+			params: newNet(0xfffffff2, "Hrpnet"),
+			err:    ErrInvalidBech32HRP,
+		},
+		{
+			name:   "space",
+			params: newNet(0xfffffff3, "hrp net"),
+			err:    ErrInvalidBech32HRP,
+		},
+		{
+			name:   "too long",
+			params: newNet(0xfffffff4, strings.Repeat("h", 84)),
+			err:    ErrInvalidBech32HRP,
+		},
+		{
+			name:   "unique",
+			params: newNet(0xfffffff5, "HRPNET"),
+			err:    nil,
+		},
+		{
+			name:   "previously registered",
+			params: newNet(0xfffffff6, "hrpnet"),
+			err:    ErrDuplicateBech32HRP,
+		},
+		{
+			name:   "prefix of registered",
+			params: newNet(0xfffffff7, "hrp"),
+			err:    nil,
+		},
+		{
+			name:   "no segwit",
+			params: newNet(0xfffffff8, ""),
+			err:    nil,
+		},
+		{
+			name:   "also no segwit",
+			params: newNet(0xfffffff9, ""),
+			err:    nil,
+		},
+	}
+
+	for _, test := range tests {
+		// VerifyBech32HRP applies the same rules without checking for
+		// duplicates.
+		wantErr := test.err
+		if wantErr == ErrDuplicateBech32HRP {
+			wantErr = nil
+		}
+		if err := test.params.VerifyBech32HRP(); err != wantErr {
+			t.Errorf("%s: VerifyBech32HRP: got error %v expected %v",
+				test.name, err, wantErr)
+		}
+
+		err := TstRegister(t, test.params)
+		if err != test.err {
+			t.Errorf("%s: Registered network with unexpected error: "+
+				"got %v expected %v", test.name, err, test.err)
+			continue
+		}
+
+		// A failed registration must not register the network.
+		if err != nil {
+			if err := TstRegister(t, test.params); err != test.err {
+				t.Errorf("%s: Failed registration was partially "+
+					"applied: got %v expected %v", test.name,
+					err, test.err)
+			}
+		}
+	}
+
+	lookupTests := []struct {
+		hrp  string
+		want *Params
+	}{
+		{"rdd", &MainNetParams},
+		{"RDD", &MainNetParams},
+		{"trdd", &TestNet3Params},
+		{"rrdd", &RegressionNetParams},
+		{"srdd", &SimNetParams},
+		{"hrpnet", tests[5].params},
+		{"hrp", tests[7].params},
+		{"hrpne", nil},
+		{"hRpNeT", nil},
+		{"", nil},
+		{"bc", nil},
+	}
+	for _, test := range lookupTests {
+		valid := IsBech32SegwitPrefix(test.hrp)
+		if valid != (test.want != nil) {
+			t.Errorf("IsBech32SegwitPrefix(%q): got %v expected %v",
+				test.hrp, valid, test.want != nil)
+		}
+
+		params, err := ParamsForBech32HRP(test.hrp)
+		if test.want == nil {
+			if err != ErrUnknownBech32HRP {
+				t.Errorf("ParamsForBech32HRP(%q): got error %v "+
+					"expected %v", test.hrp, err,
+					ErrUnknownBech32HRP)
+			}
+			continue
+		}
+		if err != nil || params != test.want {
+			t.Errorf("ParamsForBech32HRP(%q): got %v, %v expected %v",
+				test.hrp, params, err, test.want.Name)
+		}
+	}
+}
+
+// TestRegisterAliases ensures network names and aliases must be unique across
+// the default and registered networks and may be used to look the networks up.
+func TestRegisterAliases(t *testing.T) {
+	// newNet returns the parameters of a mock network with a unique magic
+	// for each test below and the passed name and aliases.
+	newNet := func(net uint32, name string, aliases ...string) *Params {
+		return &Params{
+			Name:             name,
+			Net:              rddwire.ReddcoinNet(net),
+			Aliases:          aliases,
+			PubKeyHashAddrID: 0x9e,
+			ScriptHashAddrID: 0xf8,
+			HDPrivateKeyID:   [4]byte{0x0a, 0x0b, 0x0c, 0x0d},
+			HDPublicKeyID:    [4]byte{0x0e, 0x0f, 0x10, 0x11},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		params *Params
+		err    error
+	}{
+		{
+			name:   "mainnet name",
+			params: newNet(0xffffffd0, "mainnet"),
+			err:    ErrDuplicateNetName,
+		},
+		{
+			name:   "uppercase testnet3 alias as name",
+			params: newNet(0xffffffd1, "TestNet"),
+			err:    ErrDuplicateNetName,
+		},
+		{
+			name:   "regtest name as alias",
+			params: newNet(0xffffffd2, "aliasnet", "RegTest"),
+			err:    ErrDuplicateNetName,
+		},
+		{
+			name:   "simnet alias as alias",
+			params: newNet(0xffffffd3, "aliasnet", "sim"),
+			err:    ErrDuplicateNetName,
+		},
+		{
+			name:   "alias repeats name",
+			params: newNet(0xffffffd4, "aliasnet", "AliasNet"),
+			err:    ErrDuplicateNetName,
+		},
+		{
+			name:   "alias repeated",
+			params: newNet(0xffffffd5, "aliasnet", "alias", "ALIAS"),
+			err:    ErrDuplicateNetName,
+		},
+		{
+			name:   "unique",
+			params: newNet(0xffffffd6, "aliasnet", "alias", "an"),
+			err:    nil,
+		},
+		{
+			name:   "previously registered alias",
+			params: newNet(0xffffffd7, "aliasnet2", "an"),
+			err:    ErrDuplicateNetName,
+		},
+		{
+			name:   "unique after failure",
+			params: newNet(0xffffffd8, "aliasnet2"),
+			err:    nil,
+		},
+	}
+
+	for _, test := range tests {
+		err := TstRegister(t, test.params)
+		if err != test.err {
+			t.Errorf("%s: Registered network with unexpected error: "+
+				"got %v expected %v", test.name, err, test.err)
+			continue
+		}
+
+		// A failed registration must not register the network.
+		if err != nil {
+			if _, err := ParamsForNet(test.params.Net); err == nil {
+				t.Errorf("%s: Failed registration was partially "+
+					"applied", test.name)
+			}
+		}
+	}
+
+	lookupTests := []struct {
+		name string
+		want *Params
+	}{
+		{"aliasnet", tests[6].params},
+		{"ALIAS", tests[6].params},
+		{"an", tests[6].params},
+		{"aliasnet2", tests[8].params},
+		{"main", &MainNetParams},
+		{"regression", &RegressionNetParams},
+		{"test", &TestNet3Params},
+		{"sim", &SimNetParams},
+	}
+	for _, test := range lookupTests {
+		params, err := ParamsForName(test.name)
+		if err != nil || params != test.want {
+			t.Errorf("ParamsForName(%q): got %v, %v expected %v",
+				test.name, params, err, test.want.Name)
+		}
+	}
+}
@@ -0,0 +1,500 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/reddcoin-project/rddec"
+	"github.com/reddcoin-project/rddnet/merkle"
+	"github.com/reddcoin-project/rddwire"
+)
+
+// SignetHeader marks the push of the coinbase transaction of a signet block
+// which commits to the block solution.  It is the same header Bitcoin's signet
+// uses as described in BIP0325.
+var SignetHeader = [4]byte{0xec, 0xc7, 0xda, 0xa2}
+
+// These constants are the script opcodes used by signet challenges and
+// solutions.
+const (
+	opFalse         = 0x00
+	opPushData1     = 0x4c
+	opPushData2     = 0x4d
+	op1             = 0x51
+	op16            = 0x60
+	opReturn        = 0x6a
+	opCheckSig      = 0xac
+	opCheckMultiSig = 0xae
+)
+
+// sigHashAll is the only signature hash type allowed in signet solutions.
+const sigHashAll = 1
+
+// signetBlockDataLen is the length of the block data committed to by the
+// signet signature: the version, previous block hash, signet merkle root, and
+// timestamp of the block.
+const signetBlockDataLen = 4 + rddwire.HashSize + rddwire.HashSize + 4
+
+var (
+	// ErrNotSignet describes an error where a signet operation was
+	// requested for a network which has no signet challenge.
+	ErrNotSignet = errors.New("network has no signet challenge")
+
+	// ErrUnsupportedSignetChallenge describes an error where a signet
+	// challenge is not a pay-to-pubkey or bare multisig script, which are
+	// the only challenges this package can evaluate.
+	ErrUnsupportedSignetChallenge = errors.New("unsupported signet " +
+		"challenge")
+
+	// ErrNoSignetCommitment describes an error where a block does not
+	// commit to a signet solution in its coinbase transaction.
+	ErrNoSignetCommitment = errors.New("block has no signet commitment")
+
+	// ErrInvalidSignetSolution describes an error where the signet
+	// solution of a block is malformed or can not be created with the
+	// passed keys.
+	ErrInvalidSignetSolution = errors.New("invalid signet solution")
+
+	// ErrBadSignetSignature describes an error where the signatures of a
+	// signet solution do not satisfy the challenge.
+	ErrBadSignetSignature = errors.New("signet solution does not satisfy " +
+		"the challenge")
+)
+
+// signetChallenge is a parsed challenge which requires signatures by
+// required of keys, in the order of the keys.
+type signetChallenge struct {
+	required int
+	keys     []*rddec.PublicKey
+	multisig bool
+}
+
+// pushData returns the script which pushes the passed data with the smallest
+// push opcode.
+func pushData(data []byte) []byte {
+	var script []byte
+	switch {
+	case len(data) < opPushData1:
+		script = []byte{byte(len(data))}
+	case len(data) <= 0xff:
+		script = []byte{opPushData1, byte(len(data))}
+	default:
+		script = []byte{opPushData2, byte(len(data)), byte(len(data) >> 8)}
+	}
+	return append(script, data...)
+}
+
+// parsePushes parses a script which only consists of data pushes and OP_0 and
+// returns the pushed data.  False is returned for any other script.
+func parsePushes(script []byte) ([][]byte, bool) {
+	var pushes [][]byte
+	for len(script) > 0 {
+		op := script[0]
+		script = script[1:]
+		var n int
+		switch {
+		case op < opPushData1:
+			n = int(op)
+		case op == opPushData1 && len(script) >= 1:
+			n = int(script[0])
+			script = script[1:]
+		case op == opPushData2 && len(script) >= 2:
+			n = int(binary.LittleEndian.Uint16(script))
+			script = script[2:]
+		default:
+			return nil, false
+		}
+		if n > len(script) {
+			return nil, false
+		}
+		pushes = append(pushes, script[:n])
+		script = script[n:]
+	}
+	return pushes, true
+}
+
+// parseSignetChallenge parses a pay-to-pubkey challenge, <pubkey> OP_CHECKSIG,
+// or a bare multisig challenge, OP_m <pubkey>... OP_n OP_CHECKMULTISIG.
+func parseSignetChallenge(script []byte) (*signetChallenge, error) {
+	if len(script) < 2 {
+		return nil, ErrUnsupportedSignetChallenge
+	}
+	last := script[len(script)-1]
+	switch {
+	case last == opCheckSig:
+		pushes, ok := parsePushes(script[:len(script)-1])
+		if !ok || len(pushes) != 1 {
+			return nil, ErrUnsupportedSignetChallenge
+		}
+		key, err := rddec.ParsePubKey(pushes[0], rddec.S256())
+		if err != nil {
+			return nil, ErrUnsupportedSignetChallenge
+		}
+		return &signetChallenge{required: 1,
+			keys: []*rddec.PublicKey{key}}, nil
+
+	case last == opCheckMultiSig && len(script) >= 3:
+		m, n := script[0], script[len(script)-2]
+		if m < op1 || m > op16 || n < m || n > op16 {
+			return nil, ErrUnsupportedSignetChallenge
+		}
+		pushes, ok := parsePushes(script[1 : len(script)-2])
+		if !ok || len(pushes) != int(n-op1+1) {
+			return nil, ErrUnsupportedSignetChallenge
+		}
+		challenge := &signetChallenge{required: int(m - op1 + 1),
+			multisig: true}
+		for _, push := range pushes {
+			key, err := rddec.ParsePubKey(push, rddec.S256())
+			if err != nil {
+				return nil, ErrUnsupportedSignetChallenge
+			}
+			challenge.keys = append(challenge.keys, key)
+		}
+		return challenge, nil
+	}
+	return nil, ErrUnsupportedSignetChallenge
+}
+
+// signetNet returns the network magic of the signet with the passed challenge.
+// Like on Bitcoin's signet, it is the first four bytes of the double SHA256
+// hash of the challenge serialized with its length prefix.
+func signetNet(challenge []byte) rddwire.ReddcoinNet {
+	var buf bytes.Buffer
+	writeVarInt(&buf, uint64(len(challenge)))
+	buf.Write(challenge)
+	first := sha256.Sum256(buf.Bytes())
+	second := sha256.Sum256(first[:])
+	return rddwire.ReddcoinNet(binary.LittleEndian.Uint32(second[:4]))
+}
+
+// signetPowLimit is the highest proof of work value a block can have on a
+// signet.  It is the value 0x377ae << 216, the same as on Bitcoin's signet.
+var signetPowLimit = CompactToBig(0x1e0377ae)
+
+// SigNetParams returns the parameters of a signet whose blocks must carry a
+// solution satisfying the passed challenge script.  Signets are test networks
+// for shared staging environments where only the holders of the challenge keys
+// can create blocks, so the chain is as stable as they make it.
+//
+// The network magic is derived from the challenge the way Bitcoin's signet
+// derives it, so signets with different challenges never talk to each other.
+// Also like on Bitcoin's signet, every signet shares the same genesis block,
+// which reuses the main network coinbase transaction and is not checked
+// against the challenge.  Its nonce is the first one which meets the proof of
+// work limit.  The address and extended key magics are those of the test
+// network, and no Bech32 human-readable part is defined.
+//
+// The challenge must be a pay-to-pubkey or bare multisig script, otherwise
+// ErrUnsupportedSignetChallenge is returned.  The returned parameters are not
+// registered.  They are named signet- followed by the network magic in
+// hexadecimal, such as signet-40cf030a, so signets with different challenges
+// may be registered side by side.
+func SigNetParams(challenge []byte) (*Params, error) {
+	if _, err := parseSignetChallenge(challenge); err != nil {
+		return nil, err
+	}
+
+	params := TestNet3Params.Clone()
+	params.Net = signetNet(challenge)
+	params.Name = fmt.Sprintf("signet-%08x", uint32(params.Net))
+	params.DefaultPort = "38333"
+	params.P2PPort = 38333
+	params.RPCPort = 38332
+	params.WSPort = 0
+	params.Aliases = nil
+
+	coinbase := MainNetParams.GenesisBlock.Transactions[0].Copy()
+	params.GenesisBlock = &rddwire.MsgBlock{
+		Header: rddwire.BlockHeader{
+			Version: 1,
+			MerkleRoot: merkle.Root([]rddwire.ShaHash{
+				params.TxSha(coinbase)}),
+			Timestamp: time.Unix(1598918400, 0), // 2020-09-01 00:00:00 +0000 UTC
+			Bits:      0x1e0377ae,
+			Nonce:     2418006,
+		},
+		Transactions: []*rddwire.MsgTx{coinbase},
+	}
+	hash, err := params.GenesisBlock.BlockSha()
+	if err != nil {
+		return nil, err
+	}
+	params.GenesisHash = &hash
+	params.PowLimit = new(big.Int).Set(signetPowLimit)
+	params.PowLimitBits = 0x1e0377ae
+	params.ResetMinDifficulty = false
+	params.Checkpoints = nil
+	params.HeaderSnapshots = nil
+	params.CheckpointPubKeys = nil
+	params.Bech32HRPSegwit = ""
+	params.SignetChallenge = append([]byte(nil), challenge...)
+	return params, nil
+}
+
+// findSignetCommitment returns the index of the output of the coinbase
+// transaction which commits to the signet solution, the pushes of its script,
+// and the index of the push which holds the solution.  The commitment is the
+// last OP_RETURN output with a push starting with SignetHeader.
+func findSignetCommitment(coinbase *rddwire.MsgTx) (int, [][]byte, int, bool) {
+	for i := len(coinbase.TxOut) - 1; i >= 0; i-- {
+		script := coinbase.TxOut[i].PkScript
+		if len(script) == 0 || script[0] != opReturn {
+			continue
+		}
+		pushes, ok := parsePushes(script[1:])
+		if !ok {
+			continue
+		}
+		for j, push := range pushes {
+			if bytes.HasPrefix(push, SignetHeader[:]) {
+				return i, pushes, j, true
+			}
+		}
+	}
+	return 0, nil, 0, false
+}
+
+// commitmentScript returns the OP_RETURN script of the passed pushes.
+func commitmentScript(pushes [][]byte) []byte {
+	script := []byte{opReturn}
+	for _, push := range pushes {
+		script = append(script, pushData(push)...)
+	}
+	return script
+}
+
+// setSignetSolution replaces the solution committed to by the coinbase
+// transaction, adding a commitment output when it has none.
+func setSignetSolution(coinbase *rddwire.MsgTx, solution []byte) {
+	data := append(SignetHeader[:len(SignetHeader):len(SignetHeader)],
+		solution...)
+	out, pushes, push, ok := findSignetCommitment(coinbase)
+	if !ok {
+		coinbase.TxOut = append(coinbase.TxOut, &rddwire.TxOut{
+			PkScript: commitmentScript([][]byte{data}),
+		})
+		return
+	}
+	pushes[push] = data
+	coinbase.TxOut[out].PkScript = commitmentScript(pushes)
+}
+
+// merkleRoot returns the merkle root of the transactions of the block with the
+// coinbase transaction replaced by the passed one.
+func (p *Params) merkleRoot(block *rddwire.MsgBlock, coinbase *rddwire.MsgTx) rddwire.ShaHash {
+	hashes := make([]rddwire.ShaHash, 0, len(block.Transactions))
+	hashes = append(hashes, p.TxSha(coinbase))
+	for _, tx := range block.Transactions[1:] {
+		hashes = append(hashes, p.TxSha(tx))
+	}
+	return merkle.Root(hashes)
+}
+
+// legacyTxSha returns the hash of the legacy serialization of the passed
+// transaction followed by the passed trailer.
+func legacyTxSha(tx *rddwire.MsgTx, trailer []byte) rddwire.ShaHash {
+	var buf bytes.Buffer
+	writeTx(&buf, tx, TxFormatLegacy)
+	buf.Write(trailer)
+	first := sha256.Sum256(buf.Bytes())
+	return rddwire.ShaHash(sha256.Sum256(first[:]))
+}
+
+// signetSigHash returns the signature hash of the signet solution of the block
+// along with the solution.  As described in BIP0325, a virtual transaction
+// paying to the challenge commits to the block, and the solution is the
+// signature script of a virtual transaction spending it.  The block commitment
+// uses the merkle root of the block with the solution removed from the
+// coinbase, so the solution can be added after signing.
+func (p *Params) signetSigHash(block *rddwire.MsgBlock) (rddwire.ShaHash, []byte, error) {
+	if len(p.SignetChallenge) == 0 {
+		return rddwire.ShaHash{}, nil, ErrNotSignet
+	}
+	if len(block.Transactions) == 0 || block.Transactions[0] == nil {
+		return rddwire.ShaHash{}, nil, ErrNoSignetCommitment
+	}
+	coinbase := block.Transactions[0].Copy()
+	out, pushes, push, ok := findSignetCommitment(coinbase)
+	if !ok {
+		return rddwire.ShaHash{}, nil, ErrNoSignetCommitment
+	}
+	solution := pushes[push][len(SignetHeader):]
+	pushes[push] = SignetHeader[:]
+	coinbase.TxOut[out].PkScript = commitmentScript(pushes)
+	merkleRoot := p.merkleRoot(block, coinbase)
+
+	blockData := make([]byte, 0, signetBlockDataLen)
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], uint32(block.Header.Version))
+	blockData = append(blockData, b[:]...)
+	blockData = append(blockData, block.Header.PrevBlock[:]...)
+	blockData = append(blockData, merkleRoot[:]...)
+	binary.LittleEndian.PutUint32(b[:], uint32(block.Header.Timestamp.Unix()))
+	blockData = append(blockData, b[:]...)
+
+	toSpend := &rddwire.MsgTx{
+		Version: 0,
+		TxIn: []*rddwire.TxIn{{
+			PreviousOutPoint: rddwire.OutPoint{Index: 0xffffffff},
+			SignatureScript: append([]byte{opFalse},
+				pushData(blockData)...),
+		}},
+		TxOut: []*rddwire.TxOut{{PkScript: p.SignetChallenge}},
+	}
+	toSign := &rddwire.MsgTx{
+		Version: 0,
+		TxIn: []*rddwire.TxIn{{
+			PreviousOutPoint: rddwire.OutPoint{
+				Hash: legacyTxSha(toSpend, nil),
+			},
+			SignatureScript: p.SignetChallenge,
+		}},
+		TxOut: []*rddwire.TxOut{{PkScript: []byte{opReturn}}},
+	}
+	var hashType [4]byte
+	binary.LittleEndian.PutUint32(hashType[:], sigHashAll)
+	return legacyTxSha(toSign, hashType[:]), solution, nil
+}
+
+// SetSignetSolution commits to the passed solution in the coinbase transaction
+// of the block, replacing the solution it already commits to or adding a
+// commitment output when there is none, and updates the merkle root of the
+// block.  The solution is the signature script which satisfies the challenge
+// of the network.
+func (p *Params) SetSignetSolution(block *rddwire.MsgBlock, solution []byte) error {
+	if len(p.SignetChallenge) == 0 {
+		return ErrNotSignet
+	}
+	if len(block.Transactions) == 0 || block.Transactions[0] == nil {
+		return ErrNoSignetCommitment
+	}
+	coinbase := block.Transactions[0]
+	setSignetSolution(coinbase, solution)
+	block.Header.MerkleRoot = p.merkleRoot(block, coinbase)
+	return nil
+}
+
+// SignetSigHash returns the hash the keys of the signet challenge sign for the
+// passed block.  The coinbase transaction of the block must already commit to
+// a solution, which may be empty since it is removed before hashing.
+func (p *Params) SignetSigHash(block *rddwire.MsgBlock) (rddwire.ShaHash, error) {
+	hash, _, err := p.signetSigHash(block)
+	return hash, err
+}
+
+// SignSignetBlock signs the passed block with the passed keys of the signet
+// challenge and commits to the solution with SetSignetSolution.  The nonce and
+// difficulty bits of the block are not signed, so the block may be mined after
+// signing.  ErrInvalidSignetSolution is returned when the keys are not keys of
+// the challenge or are too few to satisfy it.
+func (p *Params) SignSignetBlock(block *rddwire.MsgBlock, keys ...*rddec.PrivateKey) error {
+	if len(p.SignetChallenge) == 0 {
+		return ErrNotSignet
+	}
+	challenge, err := parseSignetChallenge(p.SignetChallenge)
+	if err != nil {
+		return err
+	}
+
+	// Signatures must be in the order of the keys of the challenge.
+	signers := make([]*rddec.PrivateKey, 0, challenge.required)
+	for _, challengeKey := range challenge.keys {
+		for _, key := range keys {
+			if key.PubKey().IsEqual(challengeKey) {
+				signers = append(signers, key)
+				break
+			}
+		}
+		if len(signers) == challenge.required {
+			break
+		}
+	}
+	if len(signers) < challenge.required {
+		return ErrInvalidSignetSolution
+	}
+
+	if err := p.SetSignetSolution(block, nil); err != nil {
+		return err
+	}
+	sigHash, _, err := p.signetSigHash(block)
+	if err != nil {
+		return err
+	}
+
+	var solution []byte
+	if challenge.multisig {
+		solution = append(solution, opFalse)
+	}
+	for _, key := range signers {
+		sig, err := key.Sign(sigHash[:])
+		if err != nil {
+			return err
+		}
+		solution = append(solution,
+			pushData(append(sig.Serialize(), sigHashAll))...)
+	}
+	return p.SetSignetSolution(block, solution)
+}
+
+// VerifySignetBlock checks that the passed block carries a solution which
+// satisfies the signet challenge of the network.  The solution must consist of
+// SIGHASH_ALL signatures in the order of the keys of the challenge, preceded
+// by OP_0 for multisig challenges.  The genesis block has no solution and must
+// not be passed.
+func (p *Params) VerifySignetBlock(block *rddwire.MsgBlock) error {
+	if len(p.SignetChallenge) == 0 {
+		return ErrNotSignet
+	}
+	challenge, err := parseSignetChallenge(p.SignetChallenge)
+	if err != nil {
+		return err
+	}
+	sigHash, solution, err := p.signetSigHash(block)
+	if err != nil {
+		return err
+	}
+
+	pushes, ok := parsePushes(solution)
+	if !ok {
+		return ErrInvalidSignetSolution
+	}
+	if challenge.multisig {
+		if len(pushes) == 0 || len(pushes[0]) != 0 {
+			return ErrInvalidSignetSolution
+		}
+		pushes = pushes[1:]
+	}
+	if len(pushes) != challenge.required {
+		return ErrInvalidSignetSolution
+	}
+
+	keys := challenge.keys
+	for _, push := range pushes {
+		if len(push) == 0 || push[len(push)-1] != sigHashAll {
+			return ErrInvalidSignetSolution
+		}
+		sig, err := rddec.ParseDERSignature(push[:len(push)-1],
+			rddec.S256())
+		if err != nil {
+			return ErrInvalidSignetSolution
+		}
+		for len(keys) > 0 && !sig.Verify(sigHash[:], keys[0]) {
+			keys = keys[1:]
+		}
+		if len(keys) == 0 {
+			return ErrBadSignetSignature
+		}
+		keys = keys[1:]
+	}
+	return nil
+}
@@ -0,0 +1,312 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/reddcoin-project/rddec"
+	"github.com/reddcoin-project/rddnet"
+	"github.com/reddcoin-project/rddwire"
+)
+
+// signetKey returns the private key with the passed hex encoded scalar, which
+// is padded to 32 bytes.
+func signetKey(t *testing.T, s string) *rddec.PrivateKey {
+	b, err := hex.DecodeString(strings.Repeat("0", 64-len(s)) + s)
+	if err != nil {
+		t.Fatalf("DecodeString: %v", err)
+	}
+	key, _ := rddec.PrivKeyFromBytes(rddec.S256(), b)
+	return key
+}
+
+// signetBlock returns an unsigned block built on the genesis block of the
+// passed signet.  Its coinbase transaction pays 50 coins to OP_TRUE.
+func signetBlock(params *rddnet.Params) *rddwire.MsgBlock {
+	return &rddwire.MsgBlock{
+		Header: rddwire.BlockHeader{
+			Version:   4,
+			PrevBlock: *params.GenesisHash,
+			Timestamp: time.Unix(1598918460, 0),
+			Bits:      params.PowLimitBits,
+		},
+		Transactions: []*rddwire.MsgTx{{
+			Version: 1,
+			TxIn: []*rddwire.TxIn{{
+				PreviousOutPoint: rddwire.OutPoint{Index: 0xffffffff},
+				SignatureScript:  []byte{0x51},
+				Sequence:         0xffffffff,
+			}},
+			TxOut: []*rddwire.TxOut{{
+				Value:    5000000000,
+				PkScript: []byte{0x51},
+			}},
+		}},
+	}
+}
+
+// TestSigNetParams ensures signets derive their network magic from the
+// challenge like Bitcoin's signet and that unsupported challenges are
+// rejected.
+func TestSigNetParams(t *testing.T) {
+	// The challenge of Bitcoin's default signet.
+	challenge, _ := hex.DecodeString("512103ad5e0edad18cb1f0fc0d28a3d4f1f" +
+		"3e445640337489abb10404f2d1e086be430210359ef5021964fe22d6f8e05" +
+		"b2463c9540ce96883fe3b278760f048f5189f2e6c452ae")
+	params, err := rddnet.SigNetParams(challenge)
+	if err != nil {
+		t.Fatalf("SigNetParams: unexpected error %v", err)
+	}
+	if params.Net != 0x40cf030a {
+		t.Errorf("Net: got %#08x want %#08x", uint32(params.Net),
+			0x40cf030a)
+	}
+	wantHash := "0000023e58ddb760b9a7aa8e2c43a3bc79bcf301d8da30eec41a010b7a32798b"
+	if params.GenesisHash.String() != wantHash {
+		t.Errorf("GenesisHash: got %v want %s", params.GenesisHash,
+			wantHash)
+	}
+	if err := params.VerifyGenesis(); err != nil {
+		t.Errorf("VerifyGenesis: %v", err)
+	}
+	// The genesis block is mined, so its hash meets the proof of work
+	// limit.
+	hash := *params.GenesisHash
+	for i := 0; i < len(hash)/2; i++ {
+		hash[i], hash[len(hash)-1-i] = hash[len(hash)-1-i], hash[i]
+	}
+	if new(big.Int).SetBytes(hash[:]).Cmp(params.PowLimit) > 0 {
+		t.Errorf("GenesisHash %v does not meet the proof of work limit",
+			params.GenesisHash)
+	}
+	if params.Name != "signet-40cf030a" {
+		t.Errorf("Name: got %s want signet-40cf030a", params.Name)
+	}
+	if !bytes.Equal(params.SignetChallenge, challenge) {
+		t.Errorf("SignetChallenge: got %x want %x",
+			params.SignetChallenge, challenge)
+	}
+	challenge[0] = 0x52
+	if params.SignetChallenge[0] != 0x51 {
+		t.Error("SignetChallenge shares memory with the passed challenge")
+	}
+	if _, err := rddnet.ParamsForName(params.Name); err == nil {
+		t.Error("SigNetParams registered the network")
+	}
+
+	// Signets with different challenges have different names.
+	other, err := rddnet.SigNetParams(challenge)
+	if err != nil {
+		t.Fatalf("SigNetParams: unexpected error %v", err)
+	}
+	if other.Name == params.Name || other.Net == params.Net {
+		t.Errorf("signets with different challenges share the name %s "+
+			"or magic %#08x", other.Name, uint32(other.Net))
+	}
+
+	pubKey := "022d8ec3e113b78c2e1fb1696664849fa7330193aff35bff41165f73a1f035471d"
+	tests := []struct {
+		name      string
+		challenge string
+	}{
+		{"empty", ""},
+		{"op true", "51"},
+		{"bad public key", "2104" + pubKey[2:] + "ac"},
+		{"trailing data", "21" + pubKey + "ac00"},
+		{"more required than keys", "5221" + pubKey + "51ae"},
+		{"missing keys", "5121" + pubKey + "52ae"},
+	}
+	for _, test := range tests {
+		challenge, _ := hex.DecodeString(test.challenge)
+		_, err := rddnet.SigNetParams(challenge)
+		if err != rddnet.ErrUnsupportedSignetChallenge {
+			t.Errorf("%s: got error %v want %v", test.name, err,
+				rddnet.ErrUnsupportedSignetChallenge)
+		}
+	}
+}
+
+// TestSignSignetBlock ensures signing a block with a pay-to-pubkey challenge
+// produces the BIP0325 solution of a local test vector which was computed
+// independently of this package.
+func TestSignSignetBlock(t *testing.T) {
+	key := signetKey(t, "7ab1e8c7c3a0f5e19f8d2b6c4e3a1d0f9b8c7d6e5f4a3b2c1d0e9f8a7b6c5d4e")
+	challenge := append([]byte{33}, key.PubKey().SerializeCompressed()...)
+	challenge = append(challenge, 0xac)
+	params, err := rddnet.SigNetParams(challenge)
+	if err != nil {
+		t.Fatalf("SigNetParams: unexpected error %v", err)
+	}
+
+	block := signetBlock(params)
+	if _, err := params.SignetSigHash(block); err != rddnet.ErrNoSignetCommitment {
+		t.Errorf("SignetSigHash: got error %v want %v", err,
+			rddnet.ErrNoSignetCommitment)
+	}
+	if err := params.SignSignetBlock(block, key); err != nil {
+		t.Fatalf("SignSignetBlock: unexpected error %v", err)
+	}
+
+	sigHash, err := params.SignetSigHash(block)
+	if err != nil {
+		t.Fatalf("SignetSigHash: unexpected error %v", err)
+	}
+	want := "86f74cf6036aae4456bdc03775017d975f16425644cc2cb11c6d10f96abc2e2e"
+	if sigHash.String() != want {
+		t.Errorf("SignetSigHash: got %v want %s", sigHash, want)
+	}
+	coinbase := block.Transactions[0]
+	if len(coinbase.TxOut) != 2 {
+		t.Fatalf("got %d coinbase outputs want 2", len(coinbase.TxOut))
+	}
+	want = "6a4c4cecc7daa247304402202620c1f1613a8eb80b0c7d404fa8f518422571" +
+		"ca8eb429379008b8657fc73d8d0220210b569ef83550f471219962b0fbd3c4e8" +
+		"ff5228b63ee727f3615087c8b89b1b01"
+	if got := hex.EncodeToString(coinbase.TxOut[1].PkScript); got != want {
+		t.Errorf("commitment: got %s want %s", got, want)
+	}
+	want = "d20ad9e0b61c4d1ad37ef32aa2709ecf02708224651aeeb493f42ac64688d322"
+	if block.Header.MerkleRoot.String() != want {
+		t.Errorf("MerkleRoot: got %v want %s", block.Header.MerkleRoot,
+			want)
+	}
+	if err := params.VerifySignetBlock(block); err != nil {
+		t.Errorf("VerifySignetBlock: unexpected error %v", err)
+	}
+
+	// Signing again replaces the commitment instead of adding another.
+	if err := params.SignSignetBlock(block, key); err != nil {
+		t.Fatalf("SignSignetBlock: unexpected error %v", err)
+	}
+	if len(coinbase.TxOut) != 2 {
+		t.Errorf("got %d coinbase outputs after signing again want 2",
+			len(coinbase.TxOut))
+	}
+
+	// Networks without a challenge are not signets.
+	_, err = rddnet.TestNet3Params.SignetSigHash(block)
+	if err != rddnet.ErrNotSignet {
+		t.Errorf("SignetSigHash: got error %v want %v", err,
+			rddnet.ErrNotSignet)
+	}
+	if err := rddnet.TestNet3Params.VerifySignetBlock(block); err != rddnet.ErrNotSignet {
+		t.Errorf("VerifySignetBlock: got error %v want %v", err,
+			rddnet.ErrNotSignet)
+	}
+}
+
+// TestVerifySignetBlock ensures multisig solutions verify and that changes to
+// the signed parts of a block invalidate its solution.
+func TestVerifySignetBlock(t *testing.T) {
+	keys := []*rddec.PrivateKey{
+		signetKey(t, "01"), signetKey(t, "02"), signetKey(t, "03"),
+	}
+	challenge := []byte{0x52}
+	for _, key := range keys {
+		challenge = append(challenge, 33)
+		challenge = append(challenge, key.PubKey().SerializeCompressed()...)
+	}
+	challenge = append(challenge, 0x53, 0xae)
+	params, err := rddnet.SigNetParams(challenge)
+	if err != nil {
+		t.Fatalf("SigNetParams: unexpected error %v", err)
+	}
+
+	// Keys may be passed in any order and extra keys are ignored.
+	block := signetBlock(params)
+	if err := params.SignSignetBlock(block, keys[2], keys[0], keys[1]); err != nil {
+		t.Fatalf("SignSignetBlock: unexpected error %v", err)
+	}
+	if err := params.VerifySignetBlock(block); err != nil {
+		t.Errorf("VerifySignetBlock: unexpected error %v", err)
+	}
+	other := signetKey(t, "04")
+	if err := params.SignSignetBlock(signetBlock(params), keys[1], other); err != rddnet.ErrInvalidSignetSolution {
+		t.Errorf("SignSignetBlock with one key: got error %v want %v",
+			err, rddnet.ErrInvalidSignetSolution)
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(b *rddwire.MsgBlock)
+		wantErr error
+	}{
+		{"nonce", func(b *rddwire.MsgBlock) {
+			b.Header.Nonce++
+		}, nil},
+		{"bits", func(b *rddwire.MsgBlock) {
+			b.Header.Bits = 0x1d00ffff
+		}, nil},
+		{"timestamp", func(b *rddwire.MsgBlock) {
+			b.Header.Timestamp = b.Header.Timestamp.Add(time.Second)
+		}, rddnet.ErrBadSignetSignature},
+		{"previous block", func(b *rddwire.MsgBlock) {
+			b.Header.PrevBlock[0] ^= 1
+		}, rddnet.ErrBadSignetSignature},
+		{"coinbase output", func(b *rddwire.MsgBlock) {
+			b.Transactions[0].TxOut[0].Value--
+		}, rddnet.ErrBadSignetSignature},
+		{"added transaction", func(b *rddwire.MsgBlock) {
+			b.Transactions = append(b.Transactions,
+				b.Transactions[0].Copy())
+		}, rddnet.ErrBadSignetSignature},
+		{"no commitment", func(b *rddwire.MsgBlock) {
+			b.Transactions[0].TxOut = b.Transactions[0].TxOut[:1]
+		}, rddnet.ErrNoSignetCommitment},
+		{"empty solution", func(b *rddwire.MsgBlock) {
+			b.Transactions[0].TxOut[1].PkScript = []byte{0x6a, 0x04,
+				0xec, 0xc7, 0xda, 0xa2}
+		}, rddnet.ErrInvalidSignetSolution},
+		{"truncated solution", func(b *rddwire.MsgBlock) {
+			params.SetSignetSolution(b, []byte{0x00, 0x48, 0x30})
+		}, rddnet.ErrInvalidSignetSolution},
+		{"missing signature", func(b *rddwire.MsgBlock) {
+			params.SetSignetSolution(b, []byte{0x00})
+		}, rddnet.ErrInvalidSignetSolution},
+	}
+	for _, test := range tests {
+		block := signetBlock(params)
+		if err := params.SignSignetBlock(block, keys[0], keys[2]); err != nil {
+			t.Fatalf("%s: SignSignetBlock: unexpected error %v",
+				test.name, err)
+		}
+		test.mutate(block)
+		if err := params.VerifySignetBlock(block); err != test.wantErr {
+			t.Errorf("%s: got error %v want %v", test.name, err,
+				test.wantErr)
+		}
+	}
+
+	// Signatures out of the order of the keys do not satisfy the
+	// challenge.
+	block = signetBlock(params)
+	if err := params.SignSignetBlock(block, keys[0], keys[1]); err != nil {
+		t.Fatalf("SignSignetBlock: unexpected error %v", err)
+	}
+	sigHash, _ := params.SignetSigHash(block)
+	solution := []byte{0x00}
+	for _, key := range []*rddec.PrivateKey{keys[1], keys[0]} {
+		signature, err := key.Sign(sigHash[:])
+		if err != nil {
+			t.Fatalf("Sign: unexpected error %v", err)
+		}
+		sig := append(signature.Serialize(), 0x01)
+		solution = append(solution, byte(len(sig)))
+		solution = append(solution, sig...)
+	}
+	if err := params.SetSignetSolution(block, solution); err != nil {
+		t.Fatalf("SetSignetSolution: unexpected error %v", err)
+	}
+	if err := params.VerifySignetBlock(block); err != rddnet.ErrBadSignetSignature {
+		t.Errorf("unordered signatures: got error %v want %v", err,
+			rddnet.ErrBadSignetSignature)
+	}
+}
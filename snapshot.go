@@ -0,0 +1,181 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/reddcoin-project/rddwire"
+)
+
+// ErrNoHeaderSnapshot describes an error where no header snapshot of a network
+// satisfies a request.
+var ErrNoHeaderSnapshot = errors.New("no header snapshot")
+
+// HeaderSnapshot is a block header embedded in the network parameters so light
+// clients may start syncing headers from it rather than from the genesis
+// block.  Snapshots are taken at checkpoints.
+type HeaderSnapshot struct {
+	// Height is the height of the block.
+	Height int64
+
+	// Header is the header of the block.
+	Header rddwire.BlockHeader
+
+	// Work is the cumulative proof of work of the chain from the genesis
+	// block up to and including the block.
+	Work *big.Int
+}
+
+// HeaderSnapshotError describes an error where a header snapshot of a network
+// is inconsistent with the other snapshots or the rest of the network
+// parameters.
+type HeaderSnapshotError struct {
+	// Index is the index of the first inconsistent snapshot in
+	// HeaderSnapshots.
+	Index int
+
+	// Description describes the inconsistency.
+	Description string
+}
+
+// Error satisfies the error interface and prints human-readable errors.
+func (e HeaderSnapshotError) Error() string {
+	return fmt.Sprintf("invalid header snapshot %d: %s", e.Index,
+		e.Description)
+}
+
+// oneLsh256 is 1 shifted left 256 bits.
+var oneLsh256 = new(big.Int).Lsh(big.NewInt(1), 256)
+
+// calcWork calculates a work value from difficulty bits.  The work of a block
+// is the expected number of hashes needed to find a hash below its target,
+// 2^256 / (target+1).  See rddchain.CalcWork for details.
+func calcWork(bits uint32) *big.Int {
+	target := CompactToBig(bits)
+	if target.Sign() <= 0 {
+		return big.NewInt(0)
+	}
+	denominator := new(big.Int).Add(target, big.NewInt(1))
+	return new(big.Int).Div(oneLsh256, denominator)
+}
+
+// VerifyHeaderSnapshots checks that the header snapshots of the network are
+// consistent.  The snapshots must be ordered from oldest to newest, and the
+// hash of each one must match the checkpoint at its height, or the genesis
+// hash for a snapshot of the genesis block.  The cumulative work of each
+// snapshot must grow by at least the work of its own header and, for
+// snapshots of consecutive blocks, each header must link to the previous one.
+// A HeaderSnapshotError for the first inconsistent snapshot is returned
+// otherwise.
+func (p *Params) VerifyHeaderSnapshots() error {
+	checkpoints := make(map[int64]*rddwire.ShaHash, len(p.Checkpoints))
+	for _, checkpoint := range p.Checkpoints {
+		checkpoints[checkpoint.Height] = checkpoint.Hash
+	}
+	if p.GenesisHash != nil {
+		checkpoints[0] = p.GenesisHash
+	}
+
+	var prevHash rddwire.ShaHash
+	for i := range p.HeaderSnapshots {
+		snapshot := &p.HeaderSnapshots[i]
+		fail := func(format string, args ...interface{}) error {
+			return HeaderSnapshotError{i, fmt.Sprintf(format, args...)}
+		}
+
+		hash, err := snapshot.Header.BlockSha()
+		if err != nil {
+			return fail("%v", err)
+		}
+		want, ok := checkpoints[snapshot.Height]
+		if !ok || want == nil {
+			return fail("no checkpoint at height %d", snapshot.Height)
+		}
+		if hash != *want {
+			return fail("hash %v does not match checkpoint %v at "+
+				"height %d", hash, want, snapshot.Height)
+		}
+
+		blockWork := calcWork(snapshot.Header.Bits)
+		if blockWork.Sign() <= 0 {
+			return fail("difficulty bits %#08x are not a positive "+
+				"target", snapshot.Header.Bits)
+		}
+		if snapshot.Work == nil || snapshot.Work.Cmp(blockWork) < 0 {
+			return fail("cumulative work %v is less than the work "+
+				"%v of the block", snapshot.Work, blockWork)
+		}
+
+		if i == 0 {
+			prevHash = hash
+			continue
+		}
+		prev := &p.HeaderSnapshots[i-1]
+		if snapshot.Height <= prev.Height {
+			return fail("height %d does not follow height %d",
+				snapshot.Height, prev.Height)
+		}
+		minWork := new(big.Int).Add(prev.Work, blockWork)
+		if snapshot.Work.Cmp(minWork) < 0 {
+			return fail("cumulative work %v does not grow by the "+
+				"work %v of the block from %v", snapshot.Work,
+				blockWork, prev.Work)
+		}
+		if snapshot.Height == prev.Height+1 &&
+			snapshot.Header.PrevBlock != prevHash {
+
+			return fail("previous block %v does not match %v at "+
+				"height %d", snapshot.Header.PrevBlock, prevHash,
+				prev.Height)
+		}
+		prevHash = hash
+	}
+	return nil
+}
+
+// copyHeaderSnapshot returns a deep copy of the passed header snapshot.
+func copyHeaderSnapshot(snapshot *HeaderSnapshot) *HeaderSnapshot {
+	return &HeaderSnapshot{
+		Height: snapshot.Height,
+		Header: snapshot.Header,
+		Work:   copyBigInt(snapshot.Work),
+	}
+}
+
+// HeaderSnapshotAt returns a copy of the header snapshot of the network at the
+// passed height after verifying all snapshots with VerifyHeaderSnapshots.
+// ErrNoHeaderSnapshot is returned when the network has no snapshot at the
+// height.
+func (p *Params) HeaderSnapshotAt(height int64) (*HeaderSnapshot, error) {
+	if err := p.VerifyHeaderSnapshots(); err != nil {
+		return nil, err
+	}
+	for i := range p.HeaderSnapshots {
+		if p.HeaderSnapshots[i].Height == height {
+			return copyHeaderSnapshot(&p.HeaderSnapshots[i]), nil
+		}
+	}
+	return nil, ErrNoHeaderSnapshot
+}
+
+// LatestHeaderSnapshot returns a copy of the newest header snapshot of the
+// network after verifying all snapshots with VerifyHeaderSnapshots.  Light
+// clients may start syncing headers from the returned header.  The default
+// networks only ship a snapshot of their genesis block so far, which does not
+// let light clients skip any headers.  ErrNoHeaderSnapshot is returned when the
+// network has no snapshots.
+func (p *Params) LatestHeaderSnapshot() (*HeaderSnapshot, error) {
+	if err := p.VerifyHeaderSnapshots(); err != nil {
+		return nil, err
+	}
+	if len(p.HeaderSnapshots) == 0 {
+		return nil, ErrNoHeaderSnapshot
+	}
+	latest := &p.HeaderSnapshots[len(p.HeaderSnapshots)-1]
+	return copyHeaderSnapshot(latest), nil
+}
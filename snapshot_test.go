@@ -0,0 +1,223 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/reddcoin-project/rddnet"
+	"github.com/reddcoin-project/rddwire"
+)
+
+// snapshotNet returns a clone of the simulation test network with header
+// snapshots of the genesis block and of two blocks built on top of it, which
+// are checkpointed.  Every block has a work of 2 at the simnet difficulty.
+func snapshotNet(t *testing.T) *rddnet.Params {
+	params := rddnet.SimNetParams.Clone()
+	params.Checkpoints = nil
+
+	header := params.GenesisBlock.Header
+	params.HeaderSnapshots = []rddnet.HeaderSnapshot{
+		{Height: 0, Header: header, Work: big.NewInt(2)},
+	}
+	for height := int64(1); height <= 2; height++ {
+		prevHash, err := header.BlockSha()
+		if err != nil {
+			t.Fatalf("BlockSha: %v", err)
+		}
+		header.PrevBlock = prevHash
+		header.Timestamp = header.Timestamp.Add(time.Minute)
+		hash, err := header.BlockSha()
+		if err != nil {
+			t.Fatalf("BlockSha: %v", err)
+		}
+
+		params.Checkpoints = append(params.Checkpoints,
+			rddnet.Checkpoint{Height: height, Hash: &hash})
+		params.HeaderSnapshots = append(params.HeaderSnapshots,
+			rddnet.HeaderSnapshot{
+				Height: height,
+				Header: header,
+				Work:   big.NewInt(2 * (height + 1)),
+			})
+	}
+	return params
+}
+
+// TestHeaderSnapshotAt ensures header snapshots are looked up by height and
+// returned as copies.
+func TestHeaderSnapshotAt(t *testing.T) {
+	params := snapshotNet(t)
+	if err := params.VerifyHeaderSnapshots(); err != nil {
+		t.Fatalf("VerifyHeaderSnapshots: unexpected error %v", err)
+	}
+
+	for i, want := range params.HeaderSnapshots {
+		got, err := params.HeaderSnapshotAt(want.Height)
+		if err != nil {
+			t.Errorf("HeaderSnapshotAt(%d): unexpected error %v",
+				want.Height, err)
+			continue
+		}
+		if got.Height != want.Height || got.Header != want.Header ||
+			got.Work.Cmp(want.Work) != 0 {
+
+			t.Errorf("HeaderSnapshotAt(%d): got %+v want %+v",
+				want.Height, got, want)
+		}
+
+		// Modifying the returned snapshot must not modify the network.
+		got.Work.SetInt64(0)
+		got.Header.Nonce++
+		if params.HeaderSnapshots[i].Work.Sign() == 0 ||
+			params.HeaderSnapshots[i].Header != want.Header {
+
+			t.Errorf("HeaderSnapshotAt(%d): returned snapshot "+
+				"shares state with the network", want.Height)
+		}
+	}
+
+	if _, err := params.HeaderSnapshotAt(3); err != rddnet.ErrNoHeaderSnapshot {
+		t.Errorf("HeaderSnapshotAt(3): got error %v want %v", err,
+			rddnet.ErrNoHeaderSnapshot)
+	}
+
+	latest, err := params.LatestHeaderSnapshot()
+	if err != nil {
+		t.Fatalf("LatestHeaderSnapshot: unexpected error %v", err)
+	}
+	if latest.Height != 2 || latest.Work.Int64() != 6 {
+		t.Errorf("LatestHeaderSnapshot: got height %d work %v want "+
+			"height 2 work 6", latest.Height, latest.Work)
+	}
+}
+
+// TestDefaultHeaderSnapshots ensures the header snapshots embedded in the
+// default networks are consistent and pins their heights, hashes, and
+// cumulative work.
+func TestDefaultHeaderSnapshots(t *testing.T) {
+	tests := []struct {
+		params *rddnet.Params
+		height int64
+		hash   string
+		work   string
+	}{
+		{&rddnet.MainNetParams, 0, "b868e0d95a3c3c0e0dadc67ee587aaf9dc8acbf99e3b4b3110fad4eb74c1decc", "100010"},
+		{&rddnet.TestNet3Params, 0, "000000000933ea01ad0ee984209779baaec3ced90fa3f408719526f8d77f4943", "100010001"},
+		{&rddnet.RegressionNetParams, -1, "", ""},
+		{&rddnet.SimNetParams, -1, "", ""},
+	}
+	for _, test := range tests {
+		latest, err := test.params.LatestHeaderSnapshot()
+		if test.height < 0 {
+			if err != rddnet.ErrNoHeaderSnapshot {
+				t.Errorf("%s: LatestHeaderSnapshot: got error "+
+					"%v want %v", test.params.Name, err,
+					rddnet.ErrNoHeaderSnapshot)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: LatestHeaderSnapshot: unexpected error %v",
+				test.params.Name, err)
+			continue
+		}
+		hash, _ := latest.Header.BlockSha()
+		if latest.Height != test.height || hash.String() != test.hash ||
+			latest.Work.Text(16) != test.work {
+
+			t.Errorf("%s: got snapshot at height %d with hash %v and "+
+				"work %x, want height %d hash %s work %s",
+				test.params.Name, latest.Height, hash,
+				latest.Work, test.height, test.hash, test.work)
+		}
+	}
+}
+
+// TestVerifyHeaderSnapshots ensures inconsistent header snapshots are reported
+// with the index of the first inconsistent snapshot and are not returned.
+func TestVerifyHeaderSnapshots(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(*rddnet.Params)
+		index  int
+	}{
+		{"genesis hash", func(p *rddnet.Params) {
+			p.HeaderSnapshots[0].Header.Nonce++
+		}, 0},
+		{"checkpoint hash", func(p *rddnet.Params) {
+			p.Checkpoints[1].Hash[0] ^= 1
+		}, 2},
+		{"no checkpoint", func(p *rddnet.Params) {
+			p.Checkpoints = p.Checkpoints[:1]
+		}, 2},
+		{"zero bits", func(p *rddnet.Params) {
+			p.HeaderSnapshots = p.HeaderSnapshots[:1]
+			p.HeaderSnapshots[0].Header.Bits = 0
+			hash, _ := p.HeaderSnapshots[0].Header.BlockSha()
+			p.GenesisHash = &hash
+		}, 0},
+		{"nil work", func(p *rddnet.Params) {
+			p.HeaderSnapshots[1].Work = nil
+		}, 1},
+		{"work below block", func(p *rddnet.Params) {
+			p.HeaderSnapshots[0].Work = big.NewInt(1)
+		}, 0},
+		{"work does not grow", func(p *rddnet.Params) {
+			p.HeaderSnapshots[2].Work = big.NewInt(5)
+		}, 2},
+		{"unordered", func(p *rddnet.Params) {
+			s := p.HeaderSnapshots
+			s[1], s[2] = s[2], s[1]
+			s[1].Work, s[2].Work = s[2].Work, s[1].Work
+		}, 2},
+		{"broken link", func(p *rddnet.Params) {
+			p.HeaderSnapshots[2].Header.PrevBlock = rddwire.ShaHash{}
+			hash, _ := p.HeaderSnapshots[2].Header.BlockSha()
+			p.Checkpoints[1].Hash = &hash
+		}, 2},
+	}
+
+	for _, test := range tests {
+		params := snapshotNet(t)
+		test.mutate(params)
+		err := params.VerifyHeaderSnapshots()
+		serr, ok := err.(rddnet.HeaderSnapshotError)
+		if !ok {
+			t.Errorf("%s: VerifyHeaderSnapshots: got error %v (%T) "+
+				"want HeaderSnapshotError", test.name, err, err)
+			continue
+		}
+		if serr.Index != test.index {
+			t.Errorf("%s: VerifyHeaderSnapshots: got index %d want "+
+				"%d (%v)", test.name, serr.Index, test.index, err)
+		}
+		if _, err := params.LatestHeaderSnapshot(); err != serr {
+			t.Errorf("%s: LatestHeaderSnapshot: got error %v want %v",
+				test.name, err, serr)
+		}
+		if _, err := params.HeaderSnapshotAt(0); err != serr {
+			t.Errorf("%s: HeaderSnapshotAt: got error %v want %v",
+				test.name, err, serr)
+		}
+	}
+
+	// Networks with inconsistent header snapshots can not be registered.
+	params := snapshotNet(t)
+	params.Name = "badsnapshotnet"
+	params.Aliases = nil
+	params.Net = rddwire.ReddcoinNet(0xffffffa0)
+	params.Bech32HRPSegwit = ""
+	params.HeaderSnapshots[1].Work = big.NewInt(3)
+	if _, ok := rddnet.TstRegister(t, params).(rddnet.HeaderSnapshotError); !ok {
+		t.Error("Register: registered network with inconsistent " +
+			"header snapshots")
+	}
+	if _, err := rddnet.ParamsForNet(params.Net); err == nil {
+		t.Error("Register: failed registration was partially applied")
+	}
+}
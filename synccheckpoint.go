@@ -0,0 +1,208 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/reddcoin-project/rddec"
+	"github.com/reddcoin-project/rddwire"
+)
+
+// SyncCheckpointVersion is the version of the sync checkpoint message created
+// by SignCheckpoint.
+const SyncCheckpointVersion = 1
+
+// syncCheckpointMsgLen is the length of a serialized sync checkpoint message,
+// which is the version followed by the block hash.
+const syncCheckpointMsgLen = 4 + rddwire.HashSize
+
+// maxSyncCheckpointSigLen is the maximum length of a DER encoded signature.
+const maxSyncCheckpointSigLen = 72
+
+var (
+	// ErrInvalidCheckpointPubKey describes an error where the parameters
+	// for a Reddcoin network could not be set due to one of its checkpoint
+	// master public keys not being a valid secp256k1 public key.
+	ErrInvalidCheckpointPubKey = errors.New("invalid checkpoint master " +
+		"public key")
+
+	// ErrNoCheckpointPubKeys describes an error where a sync checkpoint
+	// could not be verified due to the network not defining any checkpoint
+	// master public keys.
+	ErrNoCheckpointPubKeys = errors.New("no checkpoint master public keys")
+
+	// ErrMalformedSyncCheckpoint describes an error where a serialized sync
+	// checkpoint, its message, or its signature is malformed.
+	ErrMalformedSyncCheckpoint = errors.New("malformed sync checkpoint")
+
+	// ErrBadCheckpointSignature describes an error where the signature of
+	// a sync checkpoint was not made by any checkpoint master key of the
+	// network.
+	ErrBadCheckpointSignature = errors.New("sync checkpoint signature " +
+		"does not verify")
+)
+
+// SignedCheckpoint is a sync checkpoint signed by a checkpoint master key.
+// Peercoin derived networks, Reddcoin included, broadcast sync checkpoints to
+// protect the chain against forks while the proof-of-stake-velocity chain is
+// young.  Unlike a Checkpoint, a sync checkpoint only identifies the block by
+// its hash.
+//
+// The signed message is the version as a little-endian int32 followed by the
+// block hash, and the signature is the DER encoded ECDSA signature of the
+// double SHA256 hash of the message.
+type SignedCheckpoint struct {
+	Version   int32
+	Hash      rddwire.ShaHash
+	Signature []byte
+}
+
+// Message returns the serialized message signed by the sync checkpoint.
+func (c *SignedCheckpoint) Message() []byte {
+	msg := make([]byte, syncCheckpointMsgLen)
+	binary.LittleEndian.PutUint32(msg, uint32(c.Version))
+	copy(msg[4:], c.Hash[:])
+	return msg
+}
+
+// MessageHash returns the double SHA256 hash of the message signed by the sync
+// checkpoint.
+func (c *SignedCheckpoint) MessageHash() rddwire.ShaHash {
+	first := sha256.Sum256(c.Message())
+	return rddwire.ShaHash(sha256.Sum256(first[:]))
+}
+
+// Serialize returns the sync checkpoint in the format it is relayed in, which
+// is the message followed by the signature, each prefixed with its length as
+// a variable length integer.
+func (c *SignedCheckpoint) Serialize() []byte {
+	var buf bytes.Buffer
+	msg := c.Message()
+	writeVarInt(&buf, uint64(len(msg)))
+	buf.Write(msg)
+	writeVarInt(&buf, uint64(len(c.Signature)))
+	buf.Write(c.Signature)
+	return buf.Bytes()
+}
+
+// readSyncCheckpointBytes reads a byte slice prefixed with its length as a
+// single byte variable length integer, which is all the message and signature
+// of a sync checkpoint need.
+func readSyncCheckpointBytes(r *bytes.Reader, max int) ([]byte, error) {
+	n, err := r.ReadByte()
+	if err != nil || int(n) > max {
+		return nil, ErrMalformedSyncCheckpoint
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, ErrMalformedSyncCheckpoint
+	}
+	return b, nil
+}
+
+// ParseSignedCheckpoint parses a sync checkpoint serialized by Serialize.
+// ErrMalformedSyncCheckpoint is returned when the serialization is truncated,
+// has trailing bytes, or its message is not the length of a version followed
+// by a block hash.  The signature is not verified.
+func ParseSignedCheckpoint(b []byte) (*SignedCheckpoint, error) {
+	r := bytes.NewReader(b)
+	msg, err := readSyncCheckpointBytes(r, syncCheckpointMsgLen)
+	if err != nil {
+		return nil, err
+	}
+	if len(msg) != syncCheckpointMsgLen {
+		return nil, ErrMalformedSyncCheckpoint
+	}
+	sig, err := readSyncCheckpointBytes(r, maxSyncCheckpointSigLen)
+	if err != nil {
+		return nil, err
+	}
+	if r.Len() != 0 {
+		return nil, ErrMalformedSyncCheckpoint
+	}
+
+	c := &SignedCheckpoint{
+		Version:   int32(binary.LittleEndian.Uint32(msg)),
+		Signature: sig,
+	}
+	copy(c.Hash[:], msg[4:])
+	return c, nil
+}
+
+// SignCheckpoint returns a sync checkpoint of the passed block hash signed
+// with the checkpoint master private key.  Signing is deterministic as
+// described by RFC6979, so the same hash and key always produce the same
+// signature.
+func SignCheckpoint(hash *rddwire.ShaHash, key *rddec.PrivateKey) (*SignedCheckpoint, error) {
+	c := &SignedCheckpoint{Version: SyncCheckpointVersion, Hash: *hash}
+	msgHash := c.MessageHash()
+	sig, err := key.Sign(msgHash[:])
+	if err != nil {
+		return nil, err
+	}
+	c.Signature = sig.Serialize()
+	return c, nil
+}
+
+// parseCheckpointPubKeys parses the checkpoint master public keys of the
+// network.
+func (p *Params) parseCheckpointPubKeys() ([]*rddec.PublicKey, error) {
+	keys := make([]*rddec.PublicKey, 0, len(p.CheckpointPubKeys))
+	for _, b := range p.CheckpointPubKeys {
+		key, err := rddec.ParsePubKey(b, rddec.S256())
+		if err != nil {
+			return nil, ErrInvalidCheckpointPubKey
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// VerifySignedCheckpoint checks that the sync checkpoint is signed by one of
+// the checkpoint master keys of the network.  It errors with
+// ErrNoCheckpointPubKeys if the network defines no keys,
+// ErrInvalidCheckpointPubKey if one of them is invalid,
+// ErrMalformedSyncCheckpoint if the signature is not strictly DER encoded, and
+// ErrBadCheckpointSignature if no key made the signature.
+func (p *Params) VerifySignedCheckpoint(c *SignedCheckpoint) error {
+	if len(p.CheckpointPubKeys) == 0 {
+		return ErrNoCheckpointPubKeys
+	}
+	keys, err := p.parseCheckpointPubKeys()
+	if err != nil {
+		return err
+	}
+	sig, err := rddec.ParseDERSignature(c.Signature, rddec.S256())
+	if err != nil {
+		return ErrMalformedSyncCheckpoint
+	}
+
+	msgHash := c.MessageHash()
+	for _, key := range keys {
+		if sig.Verify(msgHash[:], key) {
+			return nil
+		}
+	}
+	return ErrBadCheckpointSignature
+}
+
+// VerifySyncCheckpoint parses a serialized sync checkpoint and checks it with
+// VerifySignedCheckpoint.  The parsed checkpoint is only returned when it
+// verifies.
+func (p *Params) VerifySyncCheckpoint(b []byte) (*SignedCheckpoint, error) {
+	c, err := ParseSignedCheckpoint(b)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.VerifySignedCheckpoint(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
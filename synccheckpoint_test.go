@@ -0,0 +1,205 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/reddcoin-project/rddec"
+	"github.com/reddcoin-project/rddnet"
+	"github.com/reddcoin-project/rddwire"
+)
+
+// These values are the test vector of a sync checkpoint of the simnet genesis
+// block signed with a test checkpoint master key.  They were cross-checked
+// with an independent implementation of RFC6979 signing.
+const (
+	testCheckpointPrivKey = "7ab1e8c7c3a0f5e19f8d2b6c4e3a1d0f9b8c7d6e5f4a3b2c1d0e9f8a7b6c5d4e"
+	testCheckpointPubKey  = "022d8ec3e113b78c2e1fb1696664849fa7330193aff35bff41165f73a1f035471d"
+	testCheckpointMsgHash = "7e75f065a3fc1792e3954dfa771d3945fbdb81a0c9b7fb852bc3060532cb4448"
+	testSyncCheckpoint    = "2401000000f67ad7695d9b662a72ff3d8edbbb2de0bfa67b13974bb9910d116d5cbd863e68" +
+		"46304402200f9a8e2ac2a80868b7be641a6efcdc3ec0776aa67dbbfe041d70e04e799bc58f" +
+		"0220367d0e3d70976b0dc19cfb5c2f1cffacaf1d94178768b30be7021b7efcec8d1a"
+)
+
+// checkpointKey returns the private key for the passed hex string and fails the
+// test on error.
+func checkpointKey(t *testing.T, s string) *rddec.PrivateKey {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("DecodeString: %v", err)
+	}
+	key, _ := rddec.PrivKeyFromBytes(rddec.S256(), b)
+	return key
+}
+
+// checkpointNet returns a clone of the simulation test network which accepts
+// sync checkpoints signed by the passed keys.
+func checkpointNet(keys ...*rddec.PrivateKey) *rddnet.Params {
+	params := rddnet.SimNetParams.Clone()
+	for _, key := range keys {
+		params.CheckpointPubKeys = append(params.CheckpointPubKeys,
+			key.PubKey().SerializeCompressed())
+	}
+	return params
+}
+
+// TestSignCheckpoint ensures sync checkpoints are signed and serialized as in
+// the test vector and verify against the checkpoint master keys.
+func TestSignCheckpoint(t *testing.T) {
+	key := checkpointKey(t, testCheckpointPrivKey)
+	if got := hex.EncodeToString(key.PubKey().SerializeCompressed()); got != testCheckpointPubKey {
+		t.Fatalf("public key: got %s want %s", got, testCheckpointPubKey)
+	}
+
+	c, err := rddnet.SignCheckpoint(rddnet.SimNetParams.GenesisHash, key)
+	if err != nil {
+		t.Fatalf("SignCheckpoint: unexpected error %v", err)
+	}
+	if c.Version != rddnet.SyncCheckpointVersion {
+		t.Errorf("Version: got %d want %d", c.Version,
+			rddnet.SyncCheckpointVersion)
+	}
+	msgHash := c.MessageHash()
+	if got := hex.EncodeToString(msgHash[:]); got != testCheckpointMsgHash {
+		t.Errorf("MessageHash: got %s want %s", got, testCheckpointMsgHash)
+	}
+	serialized := c.Serialize()
+	if got := hex.EncodeToString(serialized); got != testSyncCheckpoint {
+		t.Fatalf("Serialize: got %s want %s", got, testSyncCheckpoint)
+	}
+
+	params := checkpointNet(key)
+	parsed, err := params.VerifySyncCheckpoint(serialized)
+	if err != nil {
+		t.Fatalf("VerifySyncCheckpoint: unexpected error %v", err)
+	}
+	if parsed.Version != c.Version || parsed.Hash != c.Hash ||
+		!bytes.Equal(parsed.Signature, c.Signature) {
+
+		t.Errorf("VerifySyncCheckpoint: got %+v want %+v", parsed, c)
+	}
+
+	// Any of several master keys may sign, and uncompressed keys are
+	// accepted.
+	other := checkpointKey(t, "0000000000000000000000000000000000000000000000000000000000000001")
+	params = checkpointNet(other)
+	params.CheckpointPubKeys = append(params.CheckpointPubKeys,
+		key.PubKey().SerializeUncompressed())
+	if err := params.VerifySignedCheckpoint(c); err != nil {
+		t.Errorf("VerifySignedCheckpoint with second key: unexpected "+
+			"error %v", err)
+	}
+
+	// Clones do not share the keys with the original.
+	clone := params.Clone()
+	clone.CheckpointPubKeys[1][1] ^= 0xff
+	if err := params.VerifySignedCheckpoint(c); err != nil {
+		t.Errorf("VerifySignedCheckpoint after modifying clone: "+
+			"unexpected error %v", err)
+	}
+}
+
+// TestVerifySignedCheckpointErrors ensures sync checkpoints which are not
+// signed by a master key of the network or are malformed are rejected.
+func TestVerifySignedCheckpointErrors(t *testing.T) {
+	key := checkpointKey(t, testCheckpointPrivKey)
+	other := checkpointKey(t, "0000000000000000000000000000000000000000000000000000000000000001")
+	signed, err := rddnet.SignCheckpoint(rddnet.SimNetParams.GenesisHash, key)
+	if err != nil {
+		t.Fatalf("SignCheckpoint: unexpected error %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		params *rddnet.Params
+		mutate func(*rddnet.SignedCheckpoint)
+		err    error
+	}{
+		{"no keys", checkpointNet(), nil, rddnet.ErrNoCheckpointPubKeys},
+		{"other key", checkpointNet(other), nil,
+			rddnet.ErrBadCheckpointSignature},
+		{"other hash", checkpointNet(key), func(c *rddnet.SignedCheckpoint) {
+			c.Hash = *rddnet.MainNetParams.GenesisHash
+		}, rddnet.ErrBadCheckpointSignature},
+		{"other version", checkpointNet(key), func(c *rddnet.SignedCheckpoint) {
+			c.Version++
+		}, rddnet.ErrBadCheckpointSignature},
+		{"bad signature", checkpointNet(key), func(c *rddnet.SignedCheckpoint) {
+			c.Signature = c.Signature[:len(c.Signature)-1]
+		}, rddnet.ErrMalformedSyncCheckpoint},
+		{"bad key", &rddnet.Params{CheckpointPubKeys: [][]byte{{0x02}}},
+			nil, rddnet.ErrInvalidCheckpointPubKey},
+	}
+
+	for _, test := range tests {
+		c := *signed
+		c.Signature = append([]byte(nil), signed.Signature...)
+		if test.mutate != nil {
+			test.mutate(&c)
+		}
+		if err := test.params.VerifySignedCheckpoint(&c); err != test.err {
+			t.Errorf("%s: got error %v want %v", test.name, err,
+				test.err)
+		}
+	}
+}
+
+// TestParseSignedCheckpoint ensures malformed serialized sync checkpoints are
+// rejected.
+func TestParseSignedCheckpoint(t *testing.T) {
+	valid, err := hex.DecodeString(testSyncCheckpoint)
+	if err != nil {
+		t.Fatalf("DecodeString: %v", err)
+	}
+	c, err := rddnet.ParseSignedCheckpoint(valid)
+	if err != nil {
+		t.Fatalf("ParseSignedCheckpoint: unexpected error %v", err)
+	}
+	if c.Version != 1 || c.Hash != *rddnet.SimNetParams.GenesisHash {
+		t.Errorf("ParseSignedCheckpoint: got version %d hash %v",
+			c.Version, c.Hash)
+	}
+
+	tests := []struct {
+		name string
+		b    []byte
+	}{
+		{"empty", nil},
+		{"truncated message", valid[:20]},
+		{"short message", append([]byte{0x04}, valid[1:5]...)},
+		{"truncated signature", valid[:len(valid)-1]},
+		{"trailing bytes", append(append([]byte{}, valid...), 0x00)},
+		{"long signature", append(append([]byte{}, valid[:37]...),
+			append([]byte{0x49}, make([]byte, 0x49)...)...)},
+	}
+	for _, test := range tests {
+		_, err := rddnet.ParseSignedCheckpoint(test.b)
+		if err != rddnet.ErrMalformedSyncCheckpoint {
+			t.Errorf("%s: got error %v want %v", test.name, err,
+				rddnet.ErrMalformedSyncCheckpoint)
+		}
+	}
+}
+
+// TestRegisterCheckpointPubKeys ensures networks with invalid checkpoint master
+// public keys can not be registered.
+func TestRegisterCheckpointPubKeys(t *testing.T) {
+	params := rddnet.SimNetParams.Clone()
+	params.Name = "badcheckpointkeynet"
+	params.Aliases = nil
+	params.Net = rddwire.ReddcoinNet(0xffffff90)
+	params.Bech32HRPSegwit = ""
+	params.CheckpointPubKeys = [][]byte{make([]byte, 33)}
+	if err := rddnet.TstRegister(t, params); err != rddnet.ErrInvalidCheckpointPubKey {
+		t.Errorf("Register: got error %v want %v", err,
+			rddnet.ErrInvalidCheckpointPubKey)
+	}
+	if _, err := rddnet.ParamsForNet(params.Net); err == nil {
+		t.Error("Register: failed registration was partially applied")
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/reddcoin-project/rddwire"
+)
+
+// TxFormat identifies how a transaction is serialized for hashing and on the
+// wire.
+type TxFormat int
+
+// These constants define the transaction formats.
+const (
+	// TxFormatLegacy is the Bitcoin transaction format, which does not
+	// serialize the transaction timestamp.
+	TxFormatLegacy TxFormat = iota
+
+	// TxFormatPoSV is the Reddcoin proof-of-stake-velocity transaction
+	// format, which serializes the transaction timestamp as a uint32
+	// after the lock time.
+	TxFormatPoSV
+)
+
+// txFormatStrings is a map of transaction formats back to their constant names
+// for pretty printing.
+var txFormatStrings = map[TxFormat]string{
+	TxFormatLegacy: "TxFormatLegacy",
+	TxFormatPoSV:   "TxFormatPoSV",
+}
+
+// String returns the TxFormat in human-readable form.
+func (f TxFormat) String() string {
+	if s, ok := txFormatStrings[f]; ok {
+		return s
+	}
+	return fmt.Sprintf("Unknown TxFormat (%d)", int(f))
+}
+
+// TxFormat returns the format of transactions with the passed version on the
+// network.  Transactions with a version of at least PoSVTxVersion use the PoSV
+// format and all others use the legacy format.
+func (p *Params) TxFormat(version int32) TxFormat {
+	if p.PoSVTxVersion > 0 && version >= p.PoSVTxVersion {
+		return TxFormatPoSV
+	}
+	return TxFormatLegacy
+}
+
+// writeVarInt serializes n to w as a variable length integer.
+func writeVarInt(w *bytes.Buffer, n uint64) {
+	var buf [9]byte
+	switch {
+	case n < 0xfd:
+		buf[0] = byte(n)
+		w.Write(buf[:1])
+	case n <= 0xffff:
+		buf[0] = 0xfd
+		binary.LittleEndian.PutUint16(buf[1:], uint16(n))
+		w.Write(buf[:3])
+	case n <= 0xffffffff:
+		buf[0] = 0xfe
+		binary.LittleEndian.PutUint32(buf[1:], uint32(n))
+		w.Write(buf[:5])
+	default:
+		buf[0] = 0xff
+		binary.LittleEndian.PutUint64(buf[1:], n)
+		w.Write(buf[:9])
+	}
+}
+
+// writeTx serializes tx to w in the passed format.
+func writeTx(w *bytes.Buffer, tx *rddwire.MsgTx, format TxFormat) {
+	binary.Write(w, binary.LittleEndian, tx.Version)
+	writeVarInt(w, uint64(len(tx.TxIn)))
+	for _, txIn := range tx.TxIn {
+		w.Write(txIn.PreviousOutPoint.Hash[:])
+		binary.Write(w, binary.LittleEndian, txIn.PreviousOutPoint.Index)
+		writeVarInt(w, uint64(len(txIn.SignatureScript)))
+		w.Write(txIn.SignatureScript)
+		binary.Write(w, binary.LittleEndian, txIn.Sequence)
+	}
+	writeVarInt(w, uint64(len(tx.TxOut)))
+	for _, txOut := range tx.TxOut {
+		binary.Write(w, binary.LittleEndian, txOut.Value)
+		writeVarInt(w, uint64(len(txOut.PkScript)))
+		w.Write(txOut.PkScript)
+	}
+	binary.Write(w, binary.LittleEndian, tx.LockTime)
+	if format == TxFormatPoSV {
+		binary.Write(w, binary.LittleEndian, uint32(tx.Timestamp.Unix()))
+	}
+}
+
+// SerializeTx returns the serialization of the passed transaction in the
+// format the network uses for its version.
+func (p *Params) SerializeTx(tx *rddwire.MsgTx) []byte {
+	var buf bytes.Buffer
+	writeTx(&buf, tx, p.TxFormat(tx.Version))
+	return buf.Bytes()
+}
+
+// TxSha returns the hash of the passed transaction serialized in the format the
+// network uses for its version.  Unlike the TxSha method of the transaction,
+// it does not depend on which transaction versions rddwire assumes include a
+// timestamp, so it may be used for networks which introduced PoSV
+// transactions at a different version.
+func (p *Params) TxSha(tx *rddwire.MsgTx) rddwire.ShaHash {
+	first := sha256.Sum256(p.SerializeTx(tx))
+	return rddwire.ShaHash(sha256.Sum256(first[:]))
+}
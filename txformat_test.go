@@ -0,0 +1,127 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/reddcoin-project/rddnet"
+)
+
+// TestTxFormat ensures transaction versions map to the expected formats.
+func TestTxFormat(t *testing.T) {
+	legacyNet := rddnet.Params{}
+	tests := []struct {
+		name    string
+		params  *rddnet.Params
+		version int32
+		want    rddnet.TxFormat
+	}{
+		{"mainnet v1", &rddnet.MainNetParams, 1, rddnet.TxFormatLegacy},
+		{"mainnet v2", &rddnet.MainNetParams, 2, rddnet.TxFormatPoSV},
+		{"mainnet v3", &rddnet.MainNetParams, 3, rddnet.TxFormatPoSV},
+		{"testnet3 v1", &rddnet.TestNet3Params, 1, rddnet.TxFormatLegacy},
+		{"legacy net v1", &legacyNet, 1, rddnet.TxFormatLegacy},
+		{"legacy net v2", &legacyNet, 2, rddnet.TxFormatLegacy},
+	}
+	for _, test := range tests {
+		got := test.params.TxFormat(test.version)
+		if got != test.want {
+			t.Errorf("%s: TxFormat(%d): got %v want %v", test.name,
+				test.version, got, test.want)
+		}
+	}
+}
+
+// TestGenesisTxFormat ensures the genesis block of every default network is
+// hashed from the legacy serialization of its coinbase transaction, so the
+// PoSV timestamp of the transaction does not contribute to the genesis hash.
+func TestGenesisTxFormat(t *testing.T) {
+	for _, params := range defaultNets {
+		tx := params.GenesisBlock.Transactions[0]
+		if params.TxFormat(tx.Version) != rddnet.TxFormatLegacy {
+			t.Errorf("%s: genesis coinbase is not a legacy "+
+				"transaction", params.Name)
+			continue
+		}
+
+		// The serialization matches the one rddwire uses for the
+		// genesis block and the merkle root is the hash of it.
+		var buf bytes.Buffer
+		if err := tx.Serialize(&buf); err != nil {
+			t.Fatalf("%s: Serialize: %v", params.Name, err)
+		}
+		serialized := params.SerializeTx(tx)
+		if !bytes.Equal(serialized, buf.Bytes()) {
+			t.Errorf("%s: legacy serialization differs from rddwire",
+				params.Name)
+		}
+		root := params.GenesisBlock.Header.MerkleRoot
+		if params.TxSha(tx) != root {
+			t.Errorf("%s: legacy hash %v is not the merkle root %v",
+				params.Name, params.TxSha(tx), root)
+		}
+
+		// Hashing the coinbase in the PoSV format, as if the network
+		// used it for version 1 transactions, must not produce the
+		// merkle root.
+		posvNet := params.Clone()
+		posvNet.PoSVTxVersion = tx.Version
+		if posvNet.TxSha(tx) == root {
+			t.Errorf("%s: PoSV hash is the merkle root", params.Name)
+		}
+		if posvNet.VerifyGenesis() == nil {
+			t.Errorf("%s: genesis verified with PoSV coinbase",
+				params.Name)
+		}
+	}
+}
+
+// TestSerializeTxPoSV ensures PoSV transactions serialize their timestamp after
+// the lock time.
+func TestSerializeTxPoSV(t *testing.T) {
+	tx := rddnet.MainNetParams.GenesisBlock.Transactions[0].Copy()
+	legacy := rddnet.MainNetParams.SerializeTx(tx)
+
+	tx.Version = rddnet.MainNetParams.PoSVTxVersion
+	tx.Timestamp = time.Unix(1390280400, 0)
+	posv := rddnet.MainNetParams.SerializeTx(tx)
+
+	if len(posv) != len(legacy)+4 {
+		t.Fatalf("PoSV serialization is %d bytes, want %d", len(posv),
+			len(legacy)+4)
+	}
+	if !bytes.Equal(posv[4:len(legacy)], legacy[4:]) {
+		t.Error("PoSV serialization differs from legacy before the " +
+			"timestamp")
+	}
+	if ts := binary.LittleEndian.Uint32(posv[len(legacy):]); ts != 1390280400 {
+		t.Errorf("PoSV serialization has timestamp %d, want %d", ts,
+			1390280400)
+	}
+}
+
+// TestTxFormatStringer tests the stringized output for the TxFormat type.
+func TestTxFormatStringer(t *testing.T) {
+	tests := []struct {
+		in   rddnet.TxFormat
+		want string
+	}{
+		{rddnet.TxFormatLegacy, "TxFormatLegacy"},
+		{rddnet.TxFormatPoSV, "TxFormatPoSV"},
+		{0xff, "Unknown TxFormat (255)"},
+	}
+
+	for i, test := range tests {
+		result := test.in.String()
+		if result != test.want {
+			t.Errorf("String #%d\n got: %s want: %s", i, result,
+				test.want)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/reddcoin-project/rddnet/merkle"
+	"github.com/reddcoin-project/rddwire"
+)
+
+// GenesisError describes an error where the genesis block of a network is
+// inconsistent with itself or with the rest of the network parameters.
+type GenesisError struct {
+	// Field is the path of the first inconsistent field from the Params
+	// struct, such as GenesisHash or GenesisBlock.Header.MerkleRoot.
+	Field string
+
+	// Description describes the inconsistency.
+	Description string
+}
+
+// Error satisfies the error interface and prints human-readable errors.
+func (e GenesisError) Error() string {
+	return fmt.Sprintf("invalid genesis block: %s: %s", e.Field,
+		e.Description)
+}
+
+// init verifies the genesis blocks of the default networks so an inconsistent
+// constant panics when the package is loaded, like a malformed hash passed to
+// newShaHashFromStr does, rather than surfacing once nodes fail to agree on the
+// chain.
+func init() {
+	for _, params := range []*Params{&MainNetParams, &TestNet3Params,
+		&RegressionNetParams, &SimNetParams} {
+
+		if err := params.VerifyGenesis(); err != nil {
+			panic(fmt.Sprintf("%s: %v", params.Name, err))
+		}
+	}
+}
+
+// CompactToBig converts a compact representation of a whole number N to an
+// unsigned 32-bit number.  The representation is similar to IEEE754 floating
+// point numbers.  It is the same conversion as rddchain.CompactToBig, provided
+// here so tools which build genesis blocks need not depend on rddchain.
+func CompactToBig(compact uint32) *big.Int {
+	mantissa := compact & 0x007fffff
+	isNegative := compact&0x00800000 != 0
+	exponent := uint(compact >> 24)
+
+	var bn *big.Int
+	if exponent <= 3 {
+		mantissa >>= 8 * (3 - exponent)
+		bn = big.NewInt(int64(mantissa))
+	} else {
+		bn = big.NewInt(int64(mantissa))
+		bn.Lsh(bn, 8*(exponent-3))
+	}
+	if isNegative {
+		bn = bn.Neg(bn)
+	}
+	return bn
+}
+
+// VerifyGenesis checks that the genesis block of the network is consistent.
+// The merkle root of the block header must match the root computed from the
+// transactions serialized in the formats the network uses for their versions,
+// the hash of the block must match GenesisHash, and the difficulty target of
+// the block must be positive and not exceed PowLimit.  A GenesisError naming
+// the first inconsistent field is returned otherwise.  The genesis blocks of
+// the default networks are verified when the package is loaded.
+//
+// VerifyGenesis does not verify the proof of work of the genesis block.
+// Reddcoin proof of work uses the scrypt hash of the header rather than the
+// hash which identifies the block, and this package does not implement
+// scrypt.  Test networks may also carry genesis blocks mined with other hash
+// functions, such as the testnet3 genesis block taken from Bitcoin, so a
+// genesis block which passes may still not meet its own difficulty target.
+func (p *Params) VerifyGenesis() error {
+	block := p.GenesisBlock
+	if block == nil {
+		return GenesisError{"GenesisBlock", "no genesis block"}
+	}
+	if len(block.Transactions) == 0 {
+		return GenesisError{"GenesisBlock.Transactions",
+			"no coinbase transaction"}
+	}
+	if block.Header.PrevBlock != (rddwire.ShaHash{}) {
+		return GenesisError{"GenesisBlock.Header.PrevBlock",
+			fmt.Sprintf("previous block %v is not zero",
+				block.Header.PrevBlock)}
+	}
+
+	txHashes := make([]rddwire.ShaHash, 0, len(block.Transactions))
+	for i, tx := range block.Transactions {
+		if tx == nil {
+			return GenesisError{
+				fmt.Sprintf("GenesisBlock.Transactions[%d]", i),
+				"nil transaction"}
+		}
+		txHashes = append(txHashes, p.TxSha(tx))
+	}
+	root, mutated := merkle.RootMutated(txHashes)
+	if mutated {
+		return GenesisError{"GenesisBlock.Transactions",
+			"duplicate transactions mutate the merkle root"}
+	}
+	if block.Header.MerkleRoot != root {
+		return GenesisError{"GenesisBlock.Header.MerkleRoot",
+			fmt.Sprintf("merkle root %v does not match %v computed "+
+				"from the transactions", block.Header.MerkleRoot,
+				root)}
+	}
+
+	hash, err := block.BlockSha()
+	if err != nil {
+		return GenesisError{"GenesisBlock", err.Error()}
+	}
+	if p.GenesisHash == nil {
+		return GenesisError{"GenesisHash", "no genesis hash"}
+	}
+	if *p.GenesisHash != hash {
+		return GenesisError{"GenesisHash",
+			fmt.Sprintf("hash %v does not match %v computed from "+
+				"the genesis block", p.GenesisHash, hash)}
+	}
+
+	if p.PowLimit == nil {
+		return GenesisError{"PowLimit", "no proof of work limit"}
+	}
+	target := CompactToBig(block.Header.Bits)
+	if target.Sign() <= 0 {
+		return GenesisError{"GenesisBlock.Header.Bits",
+			fmt.Sprintf("difficulty bits %#08x are not a positive "+
+				"target", block.Header.Bits)}
+	}
+	if target.Cmp(p.PowLimit) > 0 {
+		return GenesisError{"GenesisBlock.Header.Bits",
+			fmt.Sprintf("target %064x of difficulty bits %#08x "+
+				"exceeds the proof of work limit %064x", target,
+				block.Header.Bits, p.PowLimit)}
+	}
+	return nil
+}
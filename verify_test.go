@@ -0,0 +1,128 @@
+// Copyright (c) 2014 Conformal Systems LLC.
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rddnet_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/reddcoin-project/rddnet"
+	"github.com/reddcoin-project/rddwire"
+)
+
+// TestVerifyGenesis ensures the genesis blocks of the default networks verify
+// and that every kind of inconsistency is reported with the inconsistent
+// field.
+func TestVerifyGenesis(t *testing.T) {
+	for _, params := range defaultNets {
+		if err := params.VerifyGenesis(); err != nil {
+			t.Errorf("%s: VerifyGenesis: unexpected error %v",
+				params.Name, err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(*rddnet.Params)
+		field  string
+	}{
+		{"no block", func(p *rddnet.Params) { p.GenesisBlock = nil },
+			"GenesisBlock"},
+		{"no transactions", func(p *rddnet.Params) {
+			p.GenesisBlock.Transactions = nil
+		}, "GenesisBlock.Transactions"},
+		{"nil transaction", func(p *rddnet.Params) {
+			p.GenesisBlock.Transactions[0] = nil
+		}, "GenesisBlock.Transactions[0]"},
+		{"duplicate transactions", func(p *rddnet.Params) {
+			txs := p.GenesisBlock.Transactions
+			p.GenesisBlock.Transactions = append(txs, txs[0])
+		}, "GenesisBlock.Transactions"},
+		{"previous block", func(p *rddnet.Params) {
+			p.GenesisBlock.Header.PrevBlock[0] = 1
+		}, "GenesisBlock.Header.PrevBlock"},
+		{"coinbase changed", func(p *rddnet.Params) {
+			p.GenesisBlock.Transactions[0].TxOut[0].Value++
+		}, "GenesisBlock.Header.MerkleRoot"},
+		{"merkle root", func(p *rddnet.Params) {
+			p.GenesisBlock.Header.MerkleRoot[0] ^= 1
+		}, "GenesisBlock.Header.MerkleRoot"},
+		{"nonce", func(p *rddnet.Params) { p.GenesisBlock.Header.Nonce++ },
+			"GenesisHash"},
+		{"hash", func(p *rddnet.Params) { p.GenesisHash[0] ^= 1 },
+			"GenesisHash"},
+		{"no hash", func(p *rddnet.Params) { p.GenesisHash = nil },
+			"GenesisHash"},
+		{"no pow limit", func(p *rddnet.Params) { p.PowLimit = nil },
+			"PowLimit"},
+		{"pow limit", func(p *rddnet.Params) { p.PowLimit = big.NewInt(1) },
+			"GenesisBlock.Header.Bits"},
+	}
+
+	for _, test := range tests {
+		params := rddnet.MainNetParams.Clone()
+		test.mutate(params)
+		err := params.VerifyGenesis()
+		gerr, ok := err.(rddnet.GenesisError)
+		if !ok {
+			t.Errorf("%s: VerifyGenesis: got error %v (%T) want "+
+				"GenesisError", test.name, err, err)
+			continue
+		}
+		if gerr.Field != test.field {
+			t.Errorf("%s: VerifyGenesis: got field %s want %s (%v)",
+				test.name, gerr.Field, test.field, err)
+		}
+	}
+
+	// Networks with an inconsistent genesis block can not be registered.
+	params := rddnet.SimNetParams.Clone()
+	params.Name = "badgenesisnet"
+	params.Aliases = nil
+	params.Net = rddwire.ReddcoinNet(0xffffffb0)
+	params.Bech32HRPSegwit = ""
+	params.GenesisHash[0] ^= 1
+	if _, ok := rddnet.TstRegister(t, params).(rddnet.GenesisError); !ok {
+		t.Error("Register: registered network with inconsistent " +
+			"genesis block")
+	}
+	if _, err := rddnet.ParamsForNet(params.Net); err == nil {
+		t.Error("Register: failed registration was partially applied")
+	}
+
+	// Nor can networks with a genesis hash but no genesis block.
+	params.GenesisHash[0] ^= 1
+	params.GenesisBlock = nil
+	err := rddnet.TstRegister(t, params)
+	if gerr, ok := err.(rddnet.GenesisError); !ok ||
+		gerr.Field != "GenesisBlock" {
+
+		t.Errorf("Register without genesis block: got error %v want "+
+			"GenesisError for GenesisBlock", err)
+	}
+}
+
+// TestCompactToBig ensures compact difficulty bits are converted like
+// rddchain converts them.
+func TestCompactToBig(t *testing.T) {
+	tests := []struct {
+		compact uint32
+		want    string
+	}{
+		{0x1d00ffff, "ffff0000000000000000000000000000000000000000000000000000"},
+		{0x207fffff, "7fffff0000000000000000000000000000000000000000000000000000000000"},
+		{0x05009234, "92340000"},
+		{0x01003456, "0"},
+		{0x01123456, "12"},
+		{0x04923456, "-12345600"},
+	}
+	for _, test := range tests {
+		want, _ := new(big.Int).SetString(test.want, 16)
+		if got := rddnet.CompactToBig(test.compact); got.Cmp(want) != 0 {
+			t.Errorf("CompactToBig(%#08x): got %x want %x",
+				test.compact, got, want)
+		}
+	}
+}